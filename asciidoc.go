@@ -0,0 +1,125 @@
+package changelog
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	asciidocTitle        = regexp.MustCompile(`(?m)^(=+)[ \t]+\S`)
+	asciidocVersionTitle = regexp.MustCompile(`^=+[ \t]+(?:(?:link:)?[^\s\[]+\[)?(?i:(?:version|release)[ \t]+)?v?` +
+		`((?:\d+[:!])?[\w.+~-]+\.[\w.+~-]+[a-zA-Z0-9]|(?i:unreleased))\]?` + headerDate)
+	asciidocMarkup = regexp.MustCompile(`(?m)^:[\w-]+:|^\[\[[^\]\n]+\]\]$|^\[(?:source|NOTE|TIP|IMPORTANT|WARNING|CAUTION|#)|` +
+		`^(?:-{4,}|={4,}|\*{4,}|\.{4,})[ \t]*$|^(?:NOTE|TIP|IMPORTANT|WARNING|CAUTION): |` +
+		`\b(?:link|xref|image):\S*\[|https?://\S+\[[^\]\n]*\]|<<[\w-]+(?:,[^>\n]*)?>>|^\*\*+[ \t]`)
+	asciidocListMarker = regexp.MustCompile(`^([ \t]*)(\*+|\.+|-)[ \t]+`)
+)
+
+// asciidocHeaders finds the version section titles of an AsciiDoc
+// changelog:
+//
+//	= Changelog
+//
+//	== 1.2.0 (2024-03-15)
+//
+//	=== Added
+//
+//	* New feature.
+//
+// The level with the most version titles holds the versions, and an entry
+// ends at the next title of the same or a higher level.
+func asciidocHeaders(content string) []header {
+	return outlineHeaders(content, asciidocTitle, asciidocVersionTitle)
+}
+
+// outlineHeaders finds the version headers of formats whose titles carry
+// their level in a run of marker characters, as AsciiDoc's "==" and Org's
+// "**" do. title matches the start of any title, with the marker run as
+// its first group; version matches a version title line, with version and
+// date groups. Versions are taken from the level with the most version
+// titles, preferring the higher level on a tie, and an entry ends at the
+// next title of the same or a higher level.
+func outlineHeaders(content string, title, version *regexp.Regexp) []header {
+	type outlineTitle struct {
+		start, level int
+		match        []int // version submatches, relative to start
+	}
+	var titles []outlineTitle
+	counts := map[int]int{}
+	best := 0
+	for _, m := range title.FindAllStringSubmatchIndex(content, -1) {
+		t := outlineTitle{start: m[0], level: m[3] - m[2]}
+		t.match = version.FindStringSubmatchIndex(content[m[0]:lineEnd(content, m[0])])
+		titles = append(titles, t)
+		if t.match == nil {
+			continue
+		}
+		counts[t.level]++
+		if best == 0 || counts[t.level] > counts[best] || (counts[t.level] == counts[best] && t.level < best) {
+			best = t.level
+		}
+	}
+	if best == 0 {
+		return nil
+	}
+
+	var headers []header
+	for _, t := range titles {
+		if t.level > best {
+			continue
+		}
+		if n := len(headers); n > 0 && headers[n-1].end == 0 {
+			headers[n-1].end = t.start
+		}
+		if t.level != best || t.match == nil {
+			continue
+		}
+		match := make([]int, 6)
+		for i := range match {
+			match[i] = -1
+			if i < len(t.match) && t.match[i] >= 0 {
+				match[i] = t.start + t.match[i]
+			}
+		}
+		headers = append(headers, header{match: match})
+	}
+	return headers
+}
+
+// looksLikeAsciiDoc reports whether content has AsciiDoc version titles
+// and markup that RDoc, which also uses "=" titles, does not have.
+func looksLikeAsciiDoc(content string) bool {
+	return asciidocMarkup.MatchString(content) && len(asciidocHeaders(content)) > 0
+}
+
+// parseAsciiDocBody splits an AsciiDoc entry body into sections by its
+// "===" titles. AsciiDoc nests list items by repeating the marker ("**")
+// rather than by indentation, so items are read from a copy of the body
+// with the markers turned into indented bullets.
+func parseAsciiDocBody(body string, firstLine int) ([]Section, []Item) {
+	sections, _ := parseBody(body, firstLine, rdocHeading)
+	lines := strings.Split(body, "\n")
+	for i, line := range lines {
+		if m := asciidocListMarker.FindStringSubmatch(line); m != nil {
+			depth := len(m[2])
+			if m[2] == "-" {
+				depth = 1
+			}
+			lines[i] = strings.Repeat("  ", depth-1) + "- " + line[len(m[0]):]
+		}
+	}
+	listed, items := parseBody(strings.Join(lines, "\n"), firstLine, rdocHeading)
+	for i := range listed {
+		listed[i].Content = sections[i].Content
+	}
+	return listed, items
+}
+
+// renderAsciiDocItems writes items as AsciiDoc "*" bullets, with one more
+// "*" for each level of nesting.
+func renderAsciiDocItems(b *strings.Builder, items []Item, depth int) {
+	for _, it := range items {
+		b.WriteString(strings.Repeat("*", depth) + " " + it.Text + "\n")
+		renderAsciiDocItems(b, it.Children, depth+1)
+	}
+}
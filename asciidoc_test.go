@@ -0,0 +1,85 @@
+package changelog
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAsciiDocFormat(t *testing.T) {
+	p := Parse(mustReadFixture(t, "CHANGELOG.adoc"))
+	if p.format != FormatAsciiDoc {
+		t.Fatalf("detected format %v, want asciidoc", p.format)
+	}
+	if got := strings.Join(p.Versions(), ","); got != "Unreleased,1.2.0,1.1.0" {
+		t.Fatalf("Versions() = %s", got)
+	}
+
+	entry, _ := p.Entry("1.2.0")
+	assertDate(t, entry.Date, 2024, time.March, 15)
+	if len(entry.Sections) != 2 || entry.Sections[0].Name != "Added" || entry.Sections[1].Category != CategoryFixed {
+		t.Fatalf("Sections = %+v", entry.Sections)
+	}
+	added := entry.Sections[0].Items
+	if len(added) != 2 || added[0].Text != "New `render` command." || len(added[0].Children) != 1 {
+		t.Errorf("Added items = %+v", added)
+	}
+	if !strings.Contains(entry.Sections[1].Content, "NOTE: Requires Go 1.22.") {
+		t.Errorf("Fixed content = %q", entry.Sections[1].Content)
+	}
+
+	entry, _ = p.Entry("1.1.0")
+	if len(entry.Items) != 1 || entry.Items[0].Text != "Initial public release." {
+		t.Errorf("Items = %+v", entry.Items)
+	}
+}
+
+func TestAsciiDocHeaders(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		version string
+		date    string
+	}{
+		{"parenthesized date", "== 1.2.0 (2024-03-15)\n", "1.2.0", "2024-03-15"},
+		{"dash date", "== v1.2.0 - 2024-03-15\n", "1.2.0", "2024-03-15"},
+		{"linked version", "== https://example.com/releases/1.2.0[1.2.0] (2024-03-15)\n", "1.2.0", "2024-03-15"},
+		{"no date", "== Version 1.2.0\n", "1.2.0", ""},
+		{"nested under a title", "= Project\n\n== History\n\n=== 1.2.0 (2024-03-15)\n", "1.2.0", "2024-03-15"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := ParseWithFormat(tt.content, FormatAsciiDoc)
+			entry, ok := p.Entry(tt.version)
+			if !ok {
+				t.Fatalf("Versions() = %v", p.Versions())
+			}
+			if entry.RawDate != tt.date {
+				t.Errorf("RawDate = %q, want %q", entry.RawDate, tt.date)
+			}
+		})
+	}
+}
+
+func TestAsciiDocEntryEndsAtSameLevel(t *testing.T) {
+	content := "== 1.1.0\n\n* Fix\n\n== Contributors\n\nEveryone.\n\n== 1.0.0\n\n* First\n"
+	entry, _ := ParseWithFormat(content, FormatAsciiDoc).Entry("1.1.0")
+	if strings.Contains(entry.Content, "Everyone") {
+		t.Errorf("Content = %q", entry.Content)
+	}
+}
+
+func TestRenderAsciiDoc(t *testing.T) {
+	date := time.Date(2024, time.March, 15, 0, 0, 0, 0, time.UTC)
+	out := Render(FormatAsciiDoc, []Entry{{Version: "1.2.0", Date: &date, Sections: []Section{
+		{Name: "Added", Items: []Item{{Text: "Feature", Children: []Item{{Text: "Detail"}}}}},
+	}}})
+	want := "== 1.2.0 (2024-03-15)\n\n=== Added\n\n* Feature\n** Detail\n"
+	if out != want {
+		t.Errorf("Render(FormatAsciiDoc) = %q, want %q", out, want)
+	}
+	entry, ok := ParseWithFormat(out, FormatAsciiDoc).Entry("1.2.0")
+	if !ok || len(entry.Sections) != 1 || len(entry.Sections[0].Items[0].Children) != 1 {
+		t.Errorf("round trip = %+v", entry)
+	}
+}
@@ -0,0 +1,28 @@
+package changelog
+
+import "regexp"
+
+// cabalHeader matches the version headings of Haskell package changelogs,
+// where the date usually follows "--": "## 1.2.3.4 -- 2024-03-15". A
+// prerelease suffix such as "-rc1" is kept as part of the version.
+// cabalDetect tells them apart from plain Markdown changelogs by that
+// separator or the "# Revision history for" title written by cabal init.
+var (
+	cabalHeader = regexp.MustCompile(`(?m)^#{1,3}[ \t]+\[?v?(\d+(?:\.\d+)+` + cabalSuffix + `|(?i:unreleased))\]?(?:\([^)\n]*\))?` +
+		`(?:[ \t]+(?:--[ \t]+|[-–—/][ \t]+)?[(_\[]?(` + dateExpr + `)[)_\]]?)?`)
+	cabalDetect = regexp.MustCompile(`(?m)^#{1,3}[ \t]+\[?v?\d+(?:\.\d+)+` + cabalSuffix + `\]?(?:\([^)\n]*\))?[ \t]+--[ \t]` +
+		`|^#[ \t]+Revision history for `)
+)
+
+// cabalSuffix matches a prerelease or build suffix after a version.
+const cabalSuffix = `(?:[-+~][0-9A-Za-z]+(?:[.+~-][0-9A-Za-z]+)*)?`
+
+// renderCabalHeader writes a Hackage-style version heading:
+//
+//	## 1.2.3.4 -- 2024-03-15
+func renderCabalHeader(e Entry) string {
+	if e.Date != nil {
+		return "## " + e.Version + " -- " + e.Date.Format("2006-01-02")
+	}
+	return "## " + e.Version
+}
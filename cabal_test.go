@@ -0,0 +1,78 @@
+package changelog
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCabalFormat(t *testing.T) {
+	p := Parse(mustReadFixture(t, "cabal_changelog.md"))
+	if p.format != FormatCabal {
+		t.Fatalf("detected format %d, want FormatCabal", p.format)
+	}
+	if got := strings.Join(p.Versions(), ","); got != "1.2.3.4,1.2.3,1.2" {
+		t.Fatalf("Versions() = %s", got)
+	}
+
+	entry, _ := p.Entry("1.2.3.4")
+	assertDate(t, entry.Date, 2024, time.March, 15)
+	if len(entry.Items) != 2 {
+		t.Errorf("Items = %+v", entry.Items)
+	}
+	entry, _ = p.Entry("1.2.3")
+	if len(entry.Sections) != 2 || entry.Sections[0].Name != "Breaking changes" {
+		t.Errorf("Sections = %+v", entry.Sections)
+	}
+
+	// Versions are ordered with the PVP scheme, where 1.2.3 < 1.2.3.0.
+	entries, err := p.Query(">=1.2.3.0")
+	if err != nil || len(entries) != 1 || entries[0].Version != "1.2.3.4" {
+		t.Errorf("Query() = %+v, %v", entries, err)
+	}
+}
+
+func TestCabalHeaders(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		version string
+		date    string
+	}{
+		{"double dash", "## 0.1.0.0 -- 2024-03-15\n", "0.1.0.0", "2024-03-15"},
+		{"italic date", "## 1.0.1 _2024-03-15_\n", "1.0.1", "2024-03-15"},
+		{"linked version", "## [1.0.1](https://hackage.haskell.org/package/foo-1.0.1) -- 2024-03-15\n", "1.0.1", "2024-03-15"},
+		{"template date", "## 0.1.0.0 -- YYYY-mm-dd\n", "0.1.0.0", ""},
+		{"prerelease", "## 0.1.0.0-rc1 -- 2024-03-01\n", "0.1.0.0-rc1", "2024-03-01"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := ParseWithFormat(tt.content, FormatCabal)
+			entry, ok := p.Entry(tt.version)
+			if !ok {
+				t.Fatalf("Versions() = %v", p.Versions())
+			}
+			if entry.RawDate != tt.date {
+				t.Errorf("RawDate = %q, want %q", entry.RawDate, tt.date)
+			}
+		})
+	}
+}
+
+func TestRenderCabal(t *testing.T) {
+	date := time.Date(2024, time.March, 15, 0, 0, 0, 0, time.UTC)
+	out := Render(FormatCabal, []Entry{{Version: "1.2.3.4", Date: &date, Items: []Item{{Text: "Fix"}}}})
+	if want := "## 1.2.3.4 -- 2024-03-15\n\n- Fix\n"; out != want {
+		t.Errorf("Render(FormatCabal) = %q, want %q", out, want)
+	}
+	entry, ok := Parse(out).Entry("1.2.3.4")
+	if !ok || entry.Date == nil {
+		t.Errorf("round trip = %+v", entry)
+	}
+
+	out = Render(FormatCabal, []Entry{{Version: "2.0.0-rc.1", Date: &date, Items: []Item{{Text: "Fix"}}}})
+	entry, ok = ParseWithFormat(out, FormatCabal).Entry("2.0.0-rc.1")
+	if !ok || entry.Date == nil || strings.TrimSpace(entry.Content) != "- Fix" {
+		t.Errorf("prerelease round trip = %+v", entry)
+	}
+}
@@ -1,8 +1,11 @@
 // Package changelog parses changelog files into structured entries.
 //
-// It supports three common formats: Keep a Changelog (## [version] - date),
-// markdown headers (## version or ### version), and setext/underline style
-// (version\n=====). Format detection is automatic by default.
+// It supports Keep a Changelog (## [version] - date), markdown headers
+// (## version or ### version), setext/underline style (version\n=====),
+// reStructuredText, RDoc (=== version / date), Debian package changelogs,
+// the %changelog section of RPM spec files, GNU NEWS and ChangeLog files,
+// Perl CPAN::Changes files, Haskell Cabal changelogs (## version --
+// date), AsciiDoc and Org-mode. Format detection is automatic by default.
 //
 // Basic usage:
 //
@@ -27,32 +30,117 @@ import (
 	"regexp"
 	"slices"
 	"strings"
+	"sync"
 	"time"
+	"unicode"
 )
 
-// Format represents a changelog file format.
+// Format identifies a changelog file format: one of the built-in formats
+// below, or one added with RegisterFormat.
 type Format int
 
 const (
 	FormatAuto          Format = iota // Auto-detect format
 	FormatKeepAChangelog              // ## [version] - date
 	FormatMarkdown                    // ## version (date)
-	FormatUnderline                   // version\n=====
+	FormatUnderline                   // version (date)\n=====
+	FormatRST                         // reStructuredText titles or .. changelog:: directives
+	FormatRDoc                        // === version / date
+	FormatDebian                      // package (version) distribution; urgency=medium
+	FormatRPM                         // * Fri Mar 15 2024 Packager <email> - version-release
+	FormatGNUNews                     // * Noteworthy changes in release version (date) [stability]
+	FormatGNUChangeLog                // date  Name  <email>
+	FormatCPAN                        // version date, with indented [Group] blocks (CPAN::Changes)
+	FormatCabal                       // ## version -- date (Haskell/Hackage)
+	FormatAsciiDoc                    // == version (date)
+	FormatOrg                         // * version <date day>
 )
 
 // Entry holds the parsed data for a single changelog version.
 type Entry struct {
+	Version string
 	Date    *time.Time
 	Content string
+
+	// Sections lists the headed blocks (### Added, ### Fixed, ...) of the
+	// entry in the order they appear.
+	Sections []Section
+
+	// Items holds the top-level bullets of the entry, across all sections,
+	// with nested bullets as children.
+	Items []Item
+
+	// URL is the target of the link reference definition for the version,
+	// such as "[1.0.0]: https://github.com/o/r/compare/v0.9.0...v1.0.0".
+	URL string
+
+	// Unreleased is set for the "Unreleased" section of a changelog, for
+	// Debian stanzas targeting the UNRELEASED distribution, and for the
+	// "?.?" placeholder release of a GNU NEWS file.
+	Unreleased bool
+
+	// RawDate is the date exactly as written in the header, kept even when
+	// it could not be parsed into Date.
+	RawDate string
+
+	// Yanked is set when the header is marked "[YANKED]", meaning the
+	// release was pulled and should not be installed.
+	Yanked bool
+
+	// Package is the package name, for formats that record it, such as
+	// Debian stanzas and RPM spec files, and for the scoped headers of
+	// monorepo changelogs ("## @scope/pkg@1.2.0", "## pkg-v1.2.0"), whose
+	// Version is then just the version.
+	Package string
+
+	// Author is the person responsible for the entry, such as the
+	// maintainer in a Debian trailer, the packager of an RPM entry or the
+	// author of a GNU ChangeLog stanza ("Jane Doe <jane@example.com>").
+	Author string
+
+	// Fields holds format-specific header metadata, such as the Debian
+	// "distribution" and "urgency" or the GNU NEWS "stability". Keys are
+	// lower case.
+	Fields map[string]string
+
+	// Position locates the entry in the parsed content.
+	Position Position
+}
+
+// Position locates an entry in the content it was parsed from, as matched
+// by the parser. Offsets are byte offsets with exclusive ends; lines are
+// 0-based and inclusive, as for sections and items.
+type Position struct {
+	// Start and End bound the whole entry, from the start of its header
+	// to the end of the last line of its content or, in Debian
+	// changelogs, of its trailer line.
+	Start, End int
+
+	// ContentStart and ContentEnd bound Content. Both are the end of the
+	// header when the entry has no content.
+	ContentStart, ContentEnd int
+
+	// HeaderLine is the line holding the version, and StartLine and
+	// EndLine the first and last lines of the entry.
+	HeaderLine, StartLine, EndLine int
+
+	// ContentStartLine and ContentEndLine are the first and last lines of
+	// Content, or -1 when the entry has no content.
+	ContentStartLine, ContentEndLine int
 }
 
 // Compiled patterns for each format.
 var (
-	keepAChangelog = regexp.MustCompile(`(?m)^##\s+\[([^\]]+)\](?:\s+-\s+(\d{4}-\d{2}-\d{2}))?`)
-	markdownHeader = regexp.MustCompile(`(?m)^#{1,3}\s+v?([\w.+-]+\.[\w.+-]+[a-zA-Z0-9])(?:\s+\((\d{4}-\d{2}-\d{2})\))?`)
-	underlineHeader = regexp.MustCompile(`(?m)^([\w.+-]+\.[\w.+-]+[a-zA-Z0-9])\n[=-]+`)
+	keepAChangelog  = regexp.MustCompile(`(?m)^##\s+\[([^\]]+)\](?:\([^)\s]+\))?` + headerDate + `(?:[ \t]+(?i:\[yanked\]))?`)
+	markdownHeader  = regexp.MustCompile(`(?m)^#{1,3}\s+v?(` + packagePrefix + `(?:\d+[:!])?[\w.+~-]+\.[\w.+~-]+[a-zA-Z0-9]|(?i:unreleased)\b)` + headerDate)
+	underlineHeader = regexp.MustCompile(`(?m)^(` + packagePrefix + `(?:\d+[:!])?[\w.+~-]+\.[\w.+~-]+[a-zA-Z0-9]|(?i:unreleased))` + headerDate + `\n[=-]+`)
 )
 
+// headerDate captures an optional date after a version, separated by
+// whitespace and optionally a dash or slash, or wrapped in parentheses:
+// "1.2.0 - 2024-03-15", "1.2.0 / 2024-03-15", "1.2.0 (March 15, 2024)".
+const headerDate = `(?:[ \t]+(?:[-–—/][ \t]+)?\(?(` + dateExpr + `)\)?)?`
+
 // Common changelog filenames in priority order.
 var changelogFilenames = []string{
 	"changelog",
@@ -65,54 +153,103 @@ var changelogFilenames = []string{
 }
 
 // Allowed changelog file extensions.
-var changelogExtensions = []string{".md", ".txt", ".rst", ".rdoc", ".markdown", ""}
+var changelogExtensions = []string{".md", ".txt", ".rst", ".rdoc", ".markdown", ".adoc", ".asciidoc", ".org", ""}
+
+// extensionFormats maps file extensions to the format ParseFile reads them
+// in. Files with other extensions are detected.
+var extensionFormats = map[string]Format{
+	".adoc":     FormatAsciiDoc,
+	".asciidoc": FormatAsciiDoc,
+	".org":      FormatOrg,
+}
+
+// header locates a version header. match holds offsets in the layout of
+// regexp's FindStringSubmatchIndex: the whole header, then the submatches,
+// of which the built-in formats have the version and the date. A non-zero
+// end is where the entry's content stops; otherwise it runs to the next
+// header.
+type header struct {
+	match []int
+	end   int
+
+	// Metadata copied to the entry, for formats that carry it.
+	pkg        string
+	author     string
+	fields     map[string]string
+	unreleased bool
+}
 
 type versionEntry struct {
 	version string
 	entry   Entry
+	match   []int // submatch offsets of the header
+	end     int   // offset where the entry's content ends
 }
 
 // Parser holds the parsed changelog data and provides access methods.
+// Content is parsed on first access. A Parser is safe for concurrent use
+// once created. WithScheme, WithDateLayouts and WithPackage return a new
+// parser and leave the receiver unchanged, so they may be called at any
+// time.
 type Parser struct {
-	content    string
-	pattern    *regexp.Regexp
-	matchGroup int
-	entries    []versionEntry
-	parsed     bool
+	content     string
+	format      Format
+	pattern     *regexp.Regexp
+	scheme      Scheme
+	dateLayouts []string
+	packageName string
+	firstLine   int // line number of content's first line, for streamed chunks
+	offset      int // byte offset of content, for streamed chunks
+	matchGroup  int
+	entries     []versionEntry
+	index       map[string]int // entry index by version, first wins
+	lines       []int          // offset of each line of content
+	links       map[string]string
+	once        sync.Once
 }
 
 // Parse creates a parser with automatic format detection.
 func Parse(content string) *Parser {
-	p := &Parser{
-		content:    content,
-		matchGroup: 1,
-	}
-	p.pattern = p.detectFormat()
-	return p
+	return ParseWithFormat(content, FormatAuto)
 }
 
-// ParseWithFormat creates a parser using the specified format.
+// ParseWithFormat creates a parser using the specified format. FormatAuto,
+// or a format that is not registered, detects the format.
 func ParseWithFormat(content string, format Format) *Parser {
 	p := &Parser{
 		content:    content,
 		matchGroup: 1,
 	}
-	switch format {
-	case FormatKeepAChangelog:
-		p.pattern = keepAChangelog
-	case FormatMarkdown:
-		p.pattern = markdownHeader
-	case FormatUnderline:
-		p.pattern = underlineHeader
-	default:
-		p.pattern = p.detectFormat()
+	if format.Handler() == nil {
+		format = detectFormat(content)
+	}
+	p.format = format
+	if f, ok := format.Handler().(*builtinFormat); ok {
+		p.pattern = f.pattern
 	}
 	return p
 }
 
+// clone returns an unparsed parser for p's content with p's configuration.
+func (p *Parser) clone() *Parser {
+	return &Parser{
+		content:     p.content,
+		format:      p.format,
+		pattern:     p.pattern,
+		scheme:      p.scheme,
+		dateLayouts: p.dateLayouts,
+		packageName: p.packageName,
+		firstLine:   p.firstLine,
+		offset:      p.offset,
+		matchGroup:  p.matchGroup,
+	}
+}
+
 // ParseWithPattern creates a parser using a custom regex pattern.
 // The pattern must have at least one capture group for the version string.
-// An optional second capture group captures the date (YYYY-MM-DD).
+// An optional second capture group captures the date, which is parsed
+// with the parser's date layouts (DefaultDateLayouts unless changed with
+// WithDateLayouts).
 // The (?m) flag is automatically added if not already present, so that
 // ^ and $ match line boundaries.
 func ParseWithPattern(content string, pattern *regexp.Regexp) *Parser {
@@ -127,13 +264,15 @@ func ParseWithPattern(content string, pattern *regexp.Regexp) *Parser {
 	}
 }
 
-// ParseFile reads and parses a changelog file.
+// ParseFile reads and parses a changelog file. AsciiDoc (.adoc,
+// .asciidoc) and Org (.org) files are parsed in those formats; the format
+// of other files is detected.
 func ParseFile(path string) (*Parser, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
-	return Parse(string(data)), nil
+	return ParseWithFormat(string(data), extensionFormats[strings.ToLower(filepath.Ext(path))]), nil
 }
 
 // FindChangelog locates a changelog file in the given directory.
@@ -216,13 +355,48 @@ func (p *Parser) Versions() []string {
 
 // Entry returns the entry for a specific version.
 func (p *Parser) Entry(version string) (Entry, bool) {
+	ve, ok := p.lookup(version)
+	return ve.entry, ok
+}
+
+// lookup finds the entry for version, which may also be given as written
+// in a scoped header ("@scope/pkg@1.2.0").
+func (p *Parser) lookup(version string) (versionEntry, bool) {
+	p.ensureParsed()
+	if i, ok := p.index[version]; ok {
+		return p.entries[i], true
+	}
+	return versionEntry{}, false
+}
+
+// Released returns the versions that are not marked Unreleased, in the
+// order they appear in the changelog. Yanked versions are included; check
+// Entry.Yanked before treating one as an upgrade target.
+func (p *Parser) Released() []string {
+	p.ensureParsed()
+	var versions []string
+	for _, ve := range p.entries {
+		if !ve.entry.Unreleased {
+			versions = append(versions, ve.version)
+		}
+	}
+	return versions
+}
+
+// Unreleased returns the entry for the Unreleased section, if there is one.
+func (p *Parser) Unreleased() (Entry, bool) {
+	ve, ok := p.findUnreleased()
+	return ve.entry, ok
+}
+
+func (p *Parser) findUnreleased() (versionEntry, bool) {
 	p.ensureParsed()
 	for _, ve := range p.entries {
-		if ve.version == version {
-			return ve.entry, true
+		if ve.entry.Unreleased {
+			return ve, true
 		}
 	}
-	return Entry{}, false
+	return versionEntry{}, false
 }
 
 // Entries returns all entries as a map. Note that Go maps do not preserve
@@ -238,11 +412,21 @@ func (p *Parser) Entries() map[string]Entry {
 
 // Between returns the content between two version headers.
 // Either version can be empty to indicate the start or end of the changelog.
+// When a version is not in the changelog, Between falls back to the
+// entries newer than oldVersion and no newer than newVersion according to
+// the parser's scheme.
+// A parser restricted to a package with WithPackage always uses its scheme.
 // Returns the content and true if found, or empty string and false if not.
 func (p *Parser) Between(oldVersion, newVersion string) (string, bool) {
+	if p.packageName != "" {
+		return p.betweenByScheme(oldVersion, newVersion)
+	}
 	oldLine := p.LineForVersion(oldVersion)
 	newLine := p.LineForVersion(newVersion)
-	lines := strings.Split(p.content, "\n")
+	if (oldVersion != "" && oldLine < 0) || (newVersion != "" && newLine < 0) {
+		return p.betweenByScheme(oldVersion, newVersion)
+	}
+	lines := p.lines
 
 	var start, end int
 	found := false
@@ -274,35 +458,85 @@ func (p *Parser) Between(oldVersion, newVersion string) (string, bool) {
 	if !found {
 		return "", false
 	}
+	if end <= start {
+		// The same header was found for both versions.
+		return "", true
+	}
 
-	result := strings.Join(lines[start:end], "\n")
-	result = strings.TrimRight(result, " \t\n")
+	to := len(p.content)
+	if end < len(lines) {
+		to = lines[end] - 1
+	}
+	result := strings.TrimRight(p.content[lines[start]:to], " \t\n")
 	return result, true
 }
 
+// betweenByScheme joins the entries in the range (oldVersion, newVersion]
+// using the parser's scheme. Entries that are contiguous in the file are
+// returned as they appear there.
+func (p *Parser) betweenByScheme(oldVersion, newVersion string) (string, bool) {
+	scheme := p.versionScheme()
+	if (oldVersion != "" && !scheme.Valid(oldVersion)) || (newVersion != "" && !scheme.Valid(newVersion)) {
+		return "", false
+	}
+
+	p.ensureParsed()
+	var parts []string
+	first, last := -1, -1
+	contiguous := true
+	for i, ve := range p.entries {
+		if !scheme.Valid(ve.version) {
+			continue
+		}
+		if oldVersion != "" && scheme.Compare(ve.version, oldVersion) <= 0 {
+			continue
+		}
+		if newVersion != "" && scheme.Compare(ve.version, newVersion) > 0 {
+			continue
+		}
+		if first < 0 {
+			first = i
+		} else if i != last+1 || p.entries[last].end != ve.match[0] {
+			contiguous = false
+		}
+		last = i
+		parts = append(parts, strings.TrimRight(p.content[ve.match[0]:ve.end], " \t\n"))
+	}
+
+	if len(parts) == 0 {
+		return "", false
+	}
+	if contiguous {
+		return strings.TrimRight(p.content[p.entries[first].match[0]:p.entries[last].end], " \t\n"), true
+	}
+	return strings.Join(parts, "\n\n"), true
+}
+
 // LineForVersion returns the 0-based line number where the given version
 // header appears, or -1 if not found. Strips a leading "v" prefix for matching.
+// Versions parsed from the changelog are located by their header, as in
+// Entry.Position.HeaderLine; others are searched for in lines that look
+// like headers.
 func (p *Parser) LineForVersion(version string) int {
 	if version == "" {
 		return -1
 	}
+	for _, v := range []string{version, strings.TrimLeft(version, "vV")} {
+		if ve, ok := p.lookup(v); ok {
+			return ve.entry.Position.HeaderLine
+		}
+	}
 
 	version = strings.TrimPrefix(version, "v")
 	version = strings.TrimPrefix(version, "V")
-	escaped := regexp.QuoteMeta(version)
-
-	// Go's regexp doesn't support lookbehinds, so we check surrounding
-	// characters manually after finding a match.
-	versionRe := regexp.MustCompile(escaped)
-	rangeRe := regexp.MustCompile(escaped + `\.\.`)
-
-	lines := strings.Split(p.content, "\n")
 
-	for i, line := range lines {
-		if !containsVersion(line, versionRe) {
+	p.ensureParsed()
+	for i := range p.lines {
+		line := lineAt(p.content, p.lines, i)
+		if !containsVersion(line, version) {
 			continue
 		}
-		if rangeRe.MatchString(line) {
+		if strings.Contains(line, version+"..") {
 			continue
 		}
 
@@ -310,42 +544,78 @@ func (p *Parser) LineForVersion(version string) int {
 		if strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") || strings.HasPrefix(line, "==") {
 			return i
 		}
-		versionLineRe := regexp.MustCompile(`^v?` + escaped + `:?\s`)
-		if versionLineRe.MatchString(line) {
-			return i
+		if rest, ok := strings.CutPrefix(strings.TrimPrefix(line, "v"), version); ok {
+			rest = strings.TrimPrefix(rest, ":")
+			if rest != "" && isSpace(rest[0]) {
+				return i
+			}
 		}
-		bracketRe := regexp.MustCompile(`^\[` + escaped + `\]`)
-		if bracketRe.MatchString(line) {
+		if strings.HasPrefix(line, "["+version+"]") {
 			return i
 		}
-		bulletRe := regexp.MustCompile(`(?i)^[+*\-]\s+(version\s+)?` + escaped)
-		if bulletRe.MatchString(line) {
+		if isBulletVersion(line, version) {
 			return i
 		}
-		dateLineRe := regexp.MustCompile(`^\d{4}-\d{2}-\d{2}`)
-		if dateLineRe.MatchString(line) {
+		if dateLine.MatchString(line) {
 			return i
 		}
 		// Check if next line is an underline
-		if i+1 < len(lines) {
-			underlineRe := regexp.MustCompile(`^[=\-+]{3,}\s*$`)
-			if underlineRe.MatchString(lines[i+1]) {
-				return i
-			}
+		if i+1 < len(p.lines) && underlineLine.MatchString(lineAt(p.content, p.lines, i+1)) {
+			return i
 		}
 	}
 
 	return -1
 }
 
+var (
+	dateLine      = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}`)
+	underlineLine = regexp.MustCompile(`^[=\-+]{3,}\s*$`)
+)
+
+// isBulletVersion reports whether line is a bullet starting with version,
+// optionally after the word "version", ignoring case.
+func isBulletVersion(line, version string) bool {
+	if line == "" || !strings.ContainsRune("+*-", rune(line[0])) {
+		return false
+	}
+	rest := strings.TrimLeft(line[1:], " \t\n\f\r")
+	if len(rest) == len(line)-1 {
+		return false
+	}
+	if hasPrefixFold(rest, version) {
+		return true
+	}
+	if !hasPrefixFold(rest, "version") {
+		return false
+	}
+	after := strings.TrimLeft(rest[len("version"):], " \t\n\f\r")
+	return len(after) < len(rest)-len("version") && hasPrefixFold(after, version)
+}
+
+func hasPrefixFold(s, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\f' || b == '\r'
+}
+
 // containsVersion checks if a line contains the version string without it
 // being a substring of a longer version (e.g. 1.0.1 should not match inside 1.0.10).
 // Allows a preceding 'v' or 'V' since version headers commonly use that prefix.
-func containsVersion(line string, versionRe *regexp.Regexp) bool {
-	for _, loc := range versionRe.FindAllStringIndex(line, -1) {
+func containsVersion(line, version string) bool {
+	for from := 0; ; {
+		i := strings.Index(line[from:], version)
+		if i < 0 {
+			return false
+		}
+		start := from + i
+		end := start + len(version)
+		from = start + 1
 		// Check char before match: must not be a dot or word char (except v/V prefix)
-		if loc[0] > 0 {
-			prev := line[loc[0]-1]
+		if start > 0 {
+			prev := line[start-1]
 			if prev == '.' {
 				continue
 			}
@@ -354,76 +624,197 @@ func containsVersion(line string, versionRe *regexp.Regexp) bool {
 			}
 		}
 		// Check char after match: must not be dot, dash, or word char
-		if loc[1] < len(line) {
-			next := line[loc[1]]
+		if end < len(line) {
+			next := line[end]
 			if next == '.' || next == '-' || isWordChar(next) {
 				continue
 			}
 		}
 		return true
 	}
-	return false
 }
 
 func isWordChar(b byte) bool {
 	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9') || b == '_'
 }
 
-func (p *Parser) detectFormat() *regexp.Regexp {
-	if keepAChangelog.MatchString(p.content) {
-		return keepAChangelog
-	}
-	if underlineHeader.MatchString(p.content) {
-		return underlineHeader
-	}
-	return markdownHeader
-}
-
 func (p *Parser) ensureParsed() {
-	if p.parsed {
-		return
-	}
-	p.parsed = true
-	p.doParse()
+	p.once.Do(p.doParse)
 }
 
 func (p *Parser) doParse() {
+	p.entries = nil
+	p.index = map[string]int{}
+	p.lines = lineOffsets(p.content)
 	if p.content == "" {
 		return
 	}
 
-	matches := p.pattern.FindAllStringSubmatchIndex(p.content, -1)
-	if matches == nil {
-		return
-	}
-
-	for i, match := range matches {
-		version := p.extractGroup(match, p.matchGroup)
-		date := p.extractDate(match)
+	p.links = parseLinks(p.content)
+	lines := p.lines
+	headers := p.findHeaders()
+	for i, h := range headers {
+		match := h.match
+		pkg, version := p.headerVersion(h)
+		if p.packageName != "" && pkg != p.packageName {
+			continue
+		}
+		p.trailingDate(match)
+		date, rawDate := p.extractDate(match)
 
 		headerEnd := match[1] // end of entire match
-		var contentEnd int
-		if i+1 < len(matches) {
-			contentEnd = matches[i+1][0] // start of next match
-		} else {
-			contentEnd = len(p.content)
+		contentEnd := h.end
+		if contentEnd == 0 {
+			if i+1 < len(headers) {
+				contentEnd = headers[i+1].match[0] // start of next match
+			} else {
+				// Link reference definitions at the end of the file belong
+				// to the whole changelog, not to the last entry.
+				contentEnd = trailingLinksStart(p.content, len(p.content))
+			}
 		}
 
-		content := strings.TrimSpace(p.content[headerEnd:contentEnd])
+		url := linkFor(p.links, version)
+		if p.format == FormatKeepAChangelog {
+			if link := inlineLink.FindStringSubmatch(p.content[match[3]+1 : match[1]]); link != nil {
+				url = link[1]
+			}
+		}
+		if pkg == "" {
+			pkg = packageFromURL(url, version)
+		}
 
-		var datep *time.Time
-		if date != nil {
-			datep = date
+		content := strings.TrimSpace(p.content[headerEnd:contentEnd])
+		bodyStart := nextLine(p.content, headerEnd)
+		var sections []Section
+		var items []Item
+		if bodyStart < contentEnd {
+			sections, items = p.parseBody(p.content[bodyStart:contentEnd], p.lineAt(lines, bodyStart))
 		}
 
 		p.entries = append(p.entries, versionEntry{
 			version: version,
+			match:   match,
+			end:     contentEnd,
 			entry: Entry{
-				Date:    datep,
-				Content: content,
+				Version:    version,
+				Date:       date,
+				RawDate:    rawDate,
+				Content:    content,
+				Sections:   sections,
+				Items:      items,
+				URL:        url,
+				Unreleased: strings.EqualFold(version, "unreleased") || h.unreleased,
+				Yanked:     yankedMarker.MatchString(p.content[match[0]:lineEnd(p.content, match[1])]),
+				Package:    pkg,
+				Author:     h.author,
+				Fields:     h.fields,
+				Position:   p.position(lines, match, headerEnd, contentEnd),
 			},
 		})
 	}
+
+	for i, ve := range p.entries {
+		keys := []string{ve.version}
+		if ve.entry.Package != "" {
+			keys = append(keys, p.extractGroup(ve.match, p.matchGroup))
+		}
+		for _, key := range keys {
+			if _, ok := p.index[key]; !ok {
+				p.index[key] = i
+			}
+		}
+	}
+}
+
+// position locates an entry whose header matched match and whose content
+// runs from headerEnd to end, before trimming.
+func (p *Parser) position(lines []int, match []int, headerEnd, end int) Position {
+	raw := p.content[headerEnd:end]
+	contentStart := end - len(strings.TrimLeftFunc(raw, unicode.IsSpace))
+	contentEnd := headerEnd + len(strings.TrimRightFunc(raw, unicode.IsSpace))
+	empty := contentEnd <= contentStart
+	if empty {
+		contentStart, contentEnd = headerEnd, headerEnd
+	}
+
+	// The entry ends on the line of the furthest of its content and its
+	// header groups, which in Debian changelogs include the trailer date.
+	last := contentEnd
+	for _, offset := range match {
+		last = max(last, offset)
+	}
+	if last > match[0] && p.content[last-1] == '\n' {
+		last--
+	}
+	end = lineEnd(p.content, last)
+
+	pos := Position{
+		Start:            p.offset + match[0],
+		End:              p.offset + end,
+		ContentStart:     p.offset + contentStart,
+		ContentEnd:       p.offset + contentEnd,
+		HeaderLine:       p.lineAt(lines, match[2]),
+		StartLine:        p.lineAt(lines, match[0]),
+		EndLine:          p.lineAt(lines, end),
+		ContentStartLine: -1,
+		ContentEndLine:   -1,
+	}
+	if !empty {
+		pos.ContentStartLine = p.lineAt(lines, contentStart)
+		pos.ContentEndLine = p.lineAt(lines, contentEnd-1)
+	}
+	return pos
+}
+
+// lineAt returns the line number of a byte offset in the content, given
+// the offsets at which its lines start.
+func (p *Parser) lineAt(lines []int, offset int) int {
+	i, found := slices.BinarySearch(lines, offset)
+	if !found {
+		i--
+	}
+	return p.firstLine + i
+}
+
+func (p *Parser) findHeaders() []header {
+	switch h := p.format.Handler().(type) {
+	case nil:
+	case *builtinFormat:
+		if h.scan != nil {
+			return h.scan(p.content)
+		}
+	default:
+		return importHeaders(h.Headers(p.content), p.content)
+	}
+	return patternHeaders(p.pattern, p.content)
+}
+
+func (p *Parser) parseBody(body string, firstLine int) ([]Section, []Item) {
+	if h := p.format.Handler(); h != nil {
+		return h.ParseBody(body, firstLine)
+	}
+	return parseBody(body, firstLine, markdownHeading)
+}
+
+var yankedMarker = regexp.MustCompile(`(?i)\[yanked\]`)
+
+// lineEnd returns the offset of the newline ending the line that contains
+// offset, or len(s) if it is the last line.
+func lineEnd(s string, offset int) int {
+	if i := strings.IndexByte(s[offset:], '\n'); i >= 0 {
+		return offset + i
+	}
+	return len(s)
+}
+
+// nextLine returns the offset of the first byte after the newline that ends
+// the line containing offset, or len(s) if there is none.
+func nextLine(s string, offset int) int {
+	if end := lineEnd(s, offset); end < len(s) {
+		return end + 1
+	}
+	return len(s)
 }
 
 func (p *Parser) extractGroup(match []int, group int) string {
@@ -435,20 +826,50 @@ func (p *Parser) extractGroup(match []int, group int) string {
 	return p.content[start:end]
 }
 
-func (p *Parser) extractDate(match []int) *time.Time {
+// trailingHeaderText matches what follows a header on its line: an
+// optional dash or slash separator, then text that may be wrapped in
+// parentheses, brackets or underscores.
+var trailingHeaderText = regexp.MustCompile(`^[ \t]+(?:[-–—/][ \t]+)?[(\[_]?([^ \t].*?)[)\]_]?[ \t]*\r?$`)
+
+// trailingDate extends a header whose date group did not match over the
+// rest of its line, when that text is a date in one of the parser's
+// layouts. Built-in patterns only capture the date forms of dateExpr, so
+// this lets custom layouts such as "02.01.2006" apply to them.
+func (p *Parser) trailingDate(match []int) {
+	group := p.matchGroup + 1
+	if group*2+1 >= len(match) || match[group*2] >= 0 {
+		return
+	}
+	lineStop := lineEnd(p.content, match[1])
+	if match[1] > 0 && p.content[match[1]-1] == '\n' {
+		return
+	}
+	m := trailingHeaderText.FindStringSubmatchIndex(p.content[match[1]:lineStop])
+	if m == nil {
+		return
+	}
+	start, end := match[1]+m[2], match[1]+m[3]
+	if _, ok := ParseDateWithLayouts(p.content[start:end], p.layouts()); !ok {
+		return
+	}
+	match[group*2], match[group*2+1] = start, end
+	match[1] = match[1] + m[1]
+}
+
+func (p *Parser) extractDate(match []int) (*time.Time, string) {
 	group := p.matchGroup + 1
 	if group*2+1 >= len(match) {
-		return nil
+		return nil, ""
 	}
 	start := match[group*2]
 	end := match[group*2+1]
 	if start < 0 {
-		return nil
+		return nil, ""
 	}
-	dateStr := p.content[start:end]
-	t, err := time.Parse("2006-01-02", dateStr)
-	if err != nil {
-		return nil
+	dateStr := strings.TrimSpace(p.content[start:end])
+	t, ok := ParseDateWithLayouts(dateStr, p.layouts())
+	if !ok {
+		return nil, dateStr
 	}
-	return &t
+	return &t, dateStr
 }
@@ -1,10 +1,12 @@
 package changelog
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -360,6 +362,22 @@ func TestBetween(t *testing.T) {
 		}
 	})
 
+	t.Run("same version", func(t *testing.T) {
+		for _, pair := range [][2]string{{"1.0.0", "1.0.0"}, {"3.0.0", "3.0.0"}, {"1.0.0", "v1.0.0"}} {
+			result, ok := p.Between(pair[0], pair[1])
+			if !ok || result != "" {
+				t.Errorf("Between(%q, %q) = %q, %v", pair[0], pair[1], result, ok)
+			}
+		}
+	})
+
+	t.Run("repeated version", func(t *testing.T) {
+		result, ok := Parse("## 1.0.0\n\n- b\n\n## 1.0.0\n\n- a\n").Between("1.0.0", "1.0.0")
+		if !ok || result != "" {
+			t.Errorf("Between() = %q, %v", result, ok)
+		}
+	})
+
 	t.Run("neither found", func(t *testing.T) {
 		_, ok := p.Between("9.0.0", "8.0.0")
 		if ok {
@@ -394,6 +412,52 @@ func TestParseFile(t *testing.T) {
 	}
 }
 
+func TestEntryPosition(t *testing.T) {
+	content := mustReadFixture(t, "keep_a_changelog.md")
+	p := Parse(content)
+	entry, _ := p.Entry("1.1.0")
+	want := Position{
+		HeaderLine: 13, StartLine: 13, EndLine: 22,
+		ContentStartLine: 15, ContentEndLine: 22,
+	}
+	got := entry.Position
+	want.Start, want.End, want.ContentStart, want.ContentEnd = got.Start, got.End, got.ContentStart, got.ContentEnd
+	if got != want {
+		t.Errorf("Position = %+v, want %+v", got, want)
+	}
+	if !strings.HasPrefix(content[got.Start:], "## [1.1.0] - 2024-03-15") || !strings.HasSuffix(content[:got.End], "connection pool") {
+		t.Errorf("entry = %q", content[got.Start:got.End])
+	}
+
+	for _, v := range p.Versions() {
+		entry, _ := p.Entry(v)
+		pos := entry.Position
+		if content[pos.ContentStart:pos.ContentEnd] != entry.Content {
+			t.Errorf("%s: content at %d:%d = %q", v, pos.ContentStart, pos.ContentEnd, content[pos.ContentStart:pos.ContentEnd])
+		}
+		if line := p.LineForVersion(v); line != pos.HeaderLine {
+			t.Errorf("%s: LineForVersion() = %d, HeaderLine = %d", v, line, pos.HeaderLine)
+		}
+	}
+
+	t.Run("empty entry", func(t *testing.T) {
+		entry, _ := Parse("## 1.1.0\n\n## 1.0.0\n- x\n").Entry("1.1.0")
+		want := Position{Start: 0, End: 8, ContentStart: 8, ContentEnd: 8, ContentStartLine: -1, ContentEndLine: -1}
+		if entry.Position != want {
+			t.Errorf("Position = %+v, want %+v", entry.Position, want)
+		}
+	})
+
+	t.Run("debian trailer", func(t *testing.T) {
+		content := mustReadFixture(t, "debian_changelog")
+		entry, _ := Parse(content).Entry("1:2.3-5")
+		pos := entry.Position
+		if pos.EndLine != 4 || pos.ContentEndLine != 2 || !strings.HasPrefix(content[pos.End-5:], "+0000") {
+			t.Errorf("Position = %+v", pos)
+		}
+	})
+}
+
 func TestFindChangelog(t *testing.T) {
 	t.Run("empty directory", func(t *testing.T) {
 		dir := t.TempDir()
@@ -421,6 +485,23 @@ func TestFindChangelog(t *testing.T) {
 			t.Errorf("expected CHANGELOG.md, got %s", path)
 		}
 	})
+
+	for _, name := range []string{"CHANGELOG.adoc", "CHANGES.asciidoc", "NEWS.org"} {
+		t.Run("finds "+name, func(t *testing.T) {
+			dir := t.TempDir()
+			content := strings.Repeat("* 1.0.0 <2024-01-01 Mon>\n- Some content that is long enough to pass the size check.\n", 2)
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			path, err := FindChangelog(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if filepath.Base(path) != name {
+				t.Errorf("expected %s, got %s", name, path)
+			}
+		})
+	}
 }
 
 func TestEdgeCases(t *testing.T) {
@@ -500,8 +581,11 @@ func TestEdgeCases(t *testing.T) {
 			t.Errorf("expected 1 version, got %d: %v", len(versions), versions)
 		}
 		entry, _ := p.Entry("1.0.0")
-		if !strings.Contains(entry.Content, "[1.0.0]: https://github.com") {
-			t.Error("expected content to contain link reference")
+		if strings.Contains(entry.Content, "[1.0.0]: https://github.com") {
+			t.Error("expected link reference to be stripped from content")
+		}
+		if entry.URL != "https://github.com/example/repo/releases/tag/v1.0.0" {
+			t.Errorf("URL = %q", entry.URL)
 		}
 	})
 
@@ -556,6 +640,58 @@ func TestEdgeCases(t *testing.T) {
 	})
 }
 
+func TestUnreleasedAndYanked(t *testing.T) {
+	content := "## [Unreleased]\n\n- Pending\n\n## [1.0.1] - 2024-02-01 [YANKED]\n\n- Broken\n\n## [1.0.0] - 2024-01-15\n\n- Initial\n"
+	p := Parse(content)
+
+	t.Run("flags", func(t *testing.T) {
+		unreleased, _ := p.Entry("Unreleased")
+		if !unreleased.Unreleased || unreleased.Yanked {
+			t.Errorf("Unreleased flags = %v/%v, want true/false", unreleased.Unreleased, unreleased.Yanked)
+		}
+		yanked, ok := p.Entry("1.0.1")
+		if !ok {
+			t.Fatal("1.0.1 not found")
+		}
+		if !yanked.Yanked || yanked.Unreleased {
+			t.Errorf("1.0.1 flags = %v/%v, want false/true", yanked.Unreleased, yanked.Yanked)
+		}
+		assertDate(t, yanked.Date, 2024, time.February, 1)
+		if strings.Contains(yanked.Content, "YANKED") {
+			t.Errorf("marker leaked into content: %q", yanked.Content)
+		}
+		released, _ := p.Entry("1.0.0")
+		if released.Yanked || released.Unreleased {
+			t.Error("expected no flags on 1.0.0")
+		}
+	})
+
+	t.Run("yanked without date", func(t *testing.T) {
+		entry, _ := Parse("## [2.0.0] [yanked]\n\n- Oops\n").Entry("2.0.0")
+		if !entry.Yanked {
+			t.Error("expected 2.0.0 to be yanked")
+		}
+	})
+
+	t.Run("released", func(t *testing.T) {
+		got := p.Released()
+		want := []string{"1.0.1", "1.0.0"}
+		if strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("Released() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("unreleased", func(t *testing.T) {
+		entry, ok := p.Unreleased()
+		if !ok || !strings.Contains(entry.Content, "Pending") {
+			t.Errorf("Unreleased() = %+v, %v", entry, ok)
+		}
+		if _, ok := Parse("## [1.0.0]\n").Unreleased(); ok {
+			t.Error("expected no Unreleased entry")
+		}
+	})
+}
+
 func TestComprehensiveFixture(t *testing.T) {
 	content := mustReadFixture(t, "comprehensive.md")
 	p := Parse(content)
@@ -585,3 +721,79 @@ func assertDate(t *testing.T, got *time.Time, year int, month time.Month, day in
 		t.Errorf("date = %v, want %d-%02d-%02d", got, year, month, day)
 	}
 }
+
+func TestParserConcurrentUse(t *testing.T) {
+	p := Parse(mustReadFixture(t, "keep_a_changelog.md"))
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Go(func() {
+			if got := len(p.Versions()); got != 4 {
+				t.Errorf("Versions() has %d versions", got)
+			}
+			if _, ok := p.Entry("1.0.1"); !ok {
+				t.Error("Entry(1.0.1) not found")
+			}
+			if line := p.LineForVersion("1.1.0"); line != 13 {
+				t.Errorf("LineForVersion(1.1.0) = %d", line)
+			}
+			if _, ok := p.Between("1.0.0", "1.1.0"); !ok {
+				t.Error("Between() failed")
+			}
+			if _, err := p.Query(">=1.0.0"); err != nil {
+				t.Error(err)
+			}
+			if got := len(p.WithScheme(CalVer).WithDateLayouts("2006-01-02").Versions()); got != 4 {
+				t.Errorf("configured copy has %d versions", got)
+			}
+		})
+	}
+	wg.Wait()
+}
+
+// largeChangelog returns a Keep a Changelog file with n versions.
+func largeChangelog(n int) string {
+	var b strings.Builder
+	b.WriteString("# Changelog\n\n")
+	for i := n; i > 0; i-- {
+		fmt.Fprintf(&b, "## [1.%d.0] - 2024-01-01\n\n### Added\n\n- Feature %d.\n- Another change.\n\n", i, i)
+	}
+	return b.String()
+}
+
+func BenchmarkParse(b *testing.B) {
+	content := largeChangelog(1000)
+	for b.Loop() {
+		Parse(content).Versions()
+	}
+}
+
+func BenchmarkEntry(b *testing.B) {
+	p := Parse(largeChangelog(1000))
+	p.Versions()
+	for b.Loop() {
+		p.Entry("1.1.0")
+	}
+}
+
+func BenchmarkLineForVersion(b *testing.B) {
+	p := Parse(largeChangelog(1000))
+	p.Versions()
+	b.Run("parsed", func(b *testing.B) {
+		for b.Loop() {
+			p.LineForVersion("v1.1.0")
+		}
+	})
+	b.Run("not parsed", func(b *testing.B) {
+		for b.Loop() {
+			p.LineForVersion("9.9.9")
+		}
+	})
+}
+
+func BenchmarkBetween(b *testing.B) {
+	p := Parse(largeChangelog(1000))
+	p.Versions()
+	for b.Loop() {
+		p.Between("1.10.0", "1.20.0")
+	}
+}
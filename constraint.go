@@ -0,0 +1,240 @@
+package changelog
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Constraint is a version range expression such as ">1.2.0, <=2.0.0",
+// "^1.4", "~2.1.0" or "1.x || >=3.0.0".
+//
+// The same syntax applies to every Scheme. Comparators separated by commas
+// or spaces must all match; groups separated by "||" are alternatives.
+// Supported operators are =, ==, !=, >, >=, <, <=, ~ (same minor, or same
+// major when only one is given), ^ (same left-most non-zero component),
+// ~> (Ruby's pessimistic operator) and npm-style hyphen ranges ("1.2.0 -
+// 1.4.0"). A bare version means "=". Partial versions and x/* wildcards
+// match every version sharing the given components, so "1.2" and "1.2.x"
+// both match 1.2.7.
+//
+// Prerelease versions are matched by plain precedence: unlike package
+// manager resolution, "<2.0.0" includes 2.0.0-rc.1, which is usually what
+// a reader of release notes wants.
+type Constraint struct {
+	raw    string
+	scheme Scheme
+	sets   [][]comparator
+}
+
+type comparator struct {
+	op       string
+	version  string
+	release  []uint64
+	wildcard bool // the version was cut short by x or *
+	numeric  bool // the version has only release components, e.g. "1.2"
+}
+
+var (
+	comparatorToken = regexp.MustCompile(`^(~>|>=|<=|!=|==|=|>|<|~|\^)?\s*([^\s,]+)`)
+	hyphenRange     = regexp.MustCompile(`^\s*([^\s,]+)\s+-\s+([^\s,]+)\s*$`)
+	numericRelease  = regexp.MustCompile(`^[vV]?\d+(?:\.\d+)*$`)
+)
+
+// ParseConstraint parses a version range expression over semantic
+// versions.
+func ParseConstraint(s string) (Constraint, error) {
+	return ParseConstraintWithScheme(s, SemVer)
+}
+
+// ParseConstraintWithScheme parses a version range expression whose
+// versions belong to the given scheme. The syntax is the same for every
+// scheme.
+func ParseConstraintWithScheme(s string, scheme Scheme) (Constraint, error) {
+	c := Constraint{raw: s, scheme: scheme}
+	for _, group := range strings.Split(s, "||") {
+		var set []comparator
+		for _, part := range strings.Split(group, ",") {
+			comps, err := parseComparators(part, scheme)
+			if err != nil {
+				return Constraint{}, fmt.Errorf("invalid constraint %q: %w", s, err)
+			}
+			set = append(set, comps...)
+		}
+		if len(set) == 0 {
+			return Constraint{}, fmt.Errorf("invalid constraint %q: empty range", s)
+		}
+		c.sets = append(c.sets, set)
+	}
+	return c, nil
+}
+
+func parseComparators(s string, scheme Scheme) ([]comparator, error) {
+	if m := hyphenRange.FindStringSubmatch(s); m != nil {
+		lo, err := newComparator(">=", m[1], scheme)
+		if err != nil {
+			return nil, err
+		}
+		hi, err := newComparator("<=", m[2], scheme)
+		if err != nil {
+			return nil, err
+		}
+		return []comparator{lo, hi}, nil
+	}
+
+	var comps []comparator
+	rest := strings.TrimSpace(s)
+	for rest != "" {
+		m := comparatorToken.FindStringSubmatch(rest)
+		if m == nil {
+			return nil, fmt.Errorf("unexpected %q", rest)
+		}
+		c, err := newComparator(m[1], m[2], scheme)
+		if err != nil {
+			return nil, err
+		}
+		comps = append(comps, c)
+		rest = strings.TrimSpace(rest[len(m[0]):])
+	}
+	return comps, nil
+}
+
+func newComparator(op, version string, scheme Scheme) (comparator, error) {
+	if op == "" || op == "==" {
+		op = "="
+	}
+	c := comparator{op: op}
+
+	components := strings.Split(version, ".")
+	for i, p := range components {
+		if p == "x" || p == "X" || p == "*" {
+			if i == 0 {
+				c.wildcard = true
+				return c, nil
+			}
+			version = strings.Join(components[:i], ".")
+			c.wildcard = true
+			break
+		}
+	}
+
+	if !scheme.Valid(version) {
+		return comparator{}, fmt.Errorf("invalid %s version %q", scheme.Name(), version)
+	}
+	c.version = version
+	c.release = scheme.Release(version)
+	if numericRelease.MatchString(version) {
+		// Keep only the components given, even if the scheme pads them.
+		c.numeric = true
+		c.release = leadingRelease(strings.TrimLeft(version, "vV"))
+	}
+	return c, nil
+}
+
+// String returns the expression the constraint was parsed from.
+func (c Constraint) String() string {
+	return c.raw
+}
+
+// Check reports whether version satisfies the constraint. Versions that
+// are not valid in the constraint's scheme never match.
+func (c Constraint) Check(version string) bool {
+	if !c.scheme.Valid(version) {
+		return false
+	}
+	for _, set := range c.sets {
+		if c.matchesAll(set, version) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c Constraint) matchesAll(set []comparator, version string) bool {
+	for _, comp := range set {
+		if !comp.matches(c.scheme, version) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c comparator) matches(scheme Scheme, version string) bool {
+	if c.version == "" {
+		return true
+	}
+
+	switch c.op {
+	case "=":
+		return c.compare(scheme, version) == 0
+	case "!=":
+		return c.compare(scheme, version) != 0
+	case ">":
+		return c.compare(scheme, version) > 0
+	case ">=":
+		return c.compare(scheme, version) >= 0
+	case "<":
+		return c.compare(scheme, version) < 0
+	case "<=":
+		return c.compare(scheme, version) <= 0
+	case "~":
+		n := 2
+		if len(c.release) == 1 {
+			n = 1
+		}
+		return c.atLeast(scheme, version, n)
+	case "^":
+		n := len(c.release)
+		for i, x := range c.release {
+			if x != 0 {
+				n = i + 1
+				break
+			}
+		}
+		return c.atLeast(scheme, version, n)
+	case "~>":
+		return c.atLeast(scheme, version, max(len(c.release)-1, 1))
+	}
+	return false
+}
+
+// atLeast reports whether version is at or above the comparator's version
+// while sharing its first n release components.
+func (c comparator) atLeast(scheme Scheme, version string, n int) bool {
+	return scheme.Compare(version, c.version) >= 0 &&
+		compareNumbers(prefix(scheme.Release(version), n), prefix(c.release, n)) == 0
+}
+
+// compare orders version against the comparator's version. A wildcard or
+// a bare release shorter than version's ("1.2" against "1.2.7") only
+// compares the components it gives.
+func (c comparator) compare(scheme Scheme, version string) int {
+	release := scheme.Release(version)
+	if (c.wildcard || c.numeric) && len(c.release) < len(release) {
+		return compareNumbers(prefix(release, len(c.release)), c.release)
+	}
+	return scheme.Compare(version, c.version)
+}
+
+func prefix(release []uint64, n int) []uint64 {
+	return release[:min(n, len(release))]
+}
+
+// Query returns the entries whose versions satisfy the constraint, in the
+// order they appear in the changelog. Versions are interpreted with the
+// parser's scheme. The endpoints of a range do not need to exist in the
+// changelog. Entries whose version is not valid in the scheme, such as
+// Unreleased, never match.
+func (p *Parser) Query(constraint string) ([]Entry, error) {
+	c, err := ParseConstraintWithScheme(constraint, p.versionScheme())
+	if err != nil {
+		return nil, err
+	}
+	var entries []Entry
+	for _, e := range p.list() {
+		if c.Check(e.Version) {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
@@ -0,0 +1,111 @@
+package changelog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConstraintCheck(t *testing.T) {
+	tests := []struct {
+		constraint string
+		match      []string
+		noMatch    []string
+	}{
+		{">1.2.0, <=2.0.0", []string{"1.2.1", "2.0.0", "2.0.0-rc.1"}, []string{"1.2.0", "2.0.1"}},
+		{">=1.0.0 <2.0.0", []string{"1.0.0", "1.9.9"}, []string{"0.9.0", "2.0.0"}},
+		{"1.2.3", []string{"1.2.3", "v1.2.3"}, []string{"1.2.4"}},
+		{"=1.2.3", []string{"1.2.3"}, []string{"1.2.4"}},
+		{"!=1.2.3", []string{"1.2.4"}, []string{"1.2.3"}},
+		{"~1.2.3", []string{"1.2.3", "1.2.9"}, []string{"1.2.2", "1.3.0"}},
+		{"~1", []string{"1.0.0", "1.9.0"}, []string{"2.0.0"}},
+		{"^1.2.3", []string{"1.2.3", "1.9.0"}, []string{"1.2.2", "2.0.0"}},
+		{"^0.2.3", []string{"0.2.3", "0.2.9"}, []string{"0.3.0"}},
+		{"^0.0.3", []string{"0.0.3"}, []string{"0.0.4"}},
+		{"~>1.2", []string{"1.2.0", "1.9.0"}, []string{"2.0.0", "1.1.0"}},
+		{"~>1.2.3", []string{"1.2.3", "1.2.9"}, []string{"1.3.0"}},
+		{"1.2.x", []string{"1.2.0", "1.2.7"}, []string{"1.3.0"}},
+		{"1.2", []string{"1.2.7"}, []string{"1.3.0"}},
+		{"*", []string{"0.0.1", "9.9.9"}, nil},
+		{">1.2", []string{"1.3.0"}, []string{"1.2.9"}},
+		{"<=1.2", []string{"1.2.9"}, []string{"1.3.0"}},
+		{"1.0.0 - 1.5.0", []string{"1.0.0", "1.5.0"}, []string{"1.5.1"}},
+		{"<1.0.0 || >=3.0.0", []string{"0.5.0", "3.1.0"}, []string{"2.0.0"}},
+		{"> 1.0.0", []string{"1.0.1"}, []string{"1.0.0"}},
+		{">=1.5.0-beta.1", []string{"1.5.0-beta.2", "1.5.0"}, []string{"1.5.0-alpha"}},
+	}
+
+	for _, tt := range tests {
+		c, err := ParseConstraint(tt.constraint)
+		if err != nil {
+			t.Errorf("ParseConstraint(%q) error: %v", tt.constraint, err)
+			continue
+		}
+		for _, s := range tt.match {
+			if !c.Check(s) {
+				t.Errorf("%q should match %s", tt.constraint, s)
+			}
+		}
+		for _, s := range tt.noMatch {
+			if c.Check(s) {
+				t.Errorf("%q should not match %s", tt.constraint, s)
+			}
+		}
+	}
+}
+
+func TestParseConstraintErrors(t *testing.T) {
+	for _, s := range []string{"", ">", ">=abc", "1.2.3.4", "1.0.0 ||"} {
+		if _, err := ParseConstraint(s); err == nil {
+			t.Errorf("ParseConstraint(%q) expected error", s)
+		}
+	}
+}
+
+func TestQuery(t *testing.T) {
+	p := Parse(mustReadFixture(t, "comprehensive.md"))
+
+	versionsOf := func(entries []Entry) string {
+		var vs []string
+		for _, e := range entries {
+			vs = append(vs, e.Version)
+		}
+		return strings.Join(vs, ",")
+	}
+
+	t.Run("endpoints missing from changelog", func(t *testing.T) {
+		entries, err := p.Query(">1.1.5, <=1.4.2")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := versionsOf(entries); got != "1.4.0-rc.1,1.3.0,1.2.0" {
+			t.Errorf("Query() = %s", got)
+		}
+	})
+
+	t.Run("caret", func(t *testing.T) {
+		entries, _ := p.Query("^1.2.0")
+		if got := versionsOf(entries); got != "1.5.0-beta.2,1.4.0-rc.1,1.3.0,1.2.0" {
+			t.Errorf("Query() = %s", got)
+		}
+	})
+
+	t.Run("skips unreleased", func(t *testing.T) {
+		entries, _ := p.Query("*")
+		if len(entries) != 7 {
+			t.Errorf("expected 7 entries, got %d", len(entries))
+		}
+	})
+
+	t.Run("entry content", func(t *testing.T) {
+		entries, _ := p.Query("=1.2.0")
+		if len(entries) != 1 || !strings.Contains(entries[0].Content, "XSS") {
+			t.Errorf("unexpected entries: %+v", entries)
+		}
+	})
+
+	t.Run("invalid constraint", func(t *testing.T) {
+		if _, err := p.Query(">>1"); err == nil {
+			t.Error("expected error")
+		}
+	})
+}
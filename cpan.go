@@ -0,0 +1,56 @@
+package changelog
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	cpanHeader = regexp.MustCompile(`(?m)^(v?\d[\w.-]*|\{\{\$NEXT\}\})` +
+		`(?:[ \t]+(` + dateExpr + `|(?i:unknown|not released)))?(?:[ \t]+([^\n]*?))?[ \t]*$`)
+	cpanDetect = regexp.MustCompile(`(?m)^(?:v?\d+\.[\w.-]+|\{\{\$NEXT\}\})(?:[ \t][^\n]*)?\n(?:[ \t]*\n)?[ \t]+(?:[-*] |\[)`)
+)
+
+// cpanHeaders finds the release lines of a CPAN::Changes file:
+//
+//	1.23 2024-03-15T10:00:00Z (TRIAL RELEASE)
+//	  [Bug Fixes]
+//	  - Fixed a thing.
+//
+// Anything after the date is kept as the "note" field. The Dist::Zilla
+// placeholder "{{$NEXT}}" is marked Unreleased, and so is a release dated
+// "Unknown" or "Not Released", which have no date.
+func cpanHeaders(content string) []header {
+	var headers []header
+	for _, m := range cpanHeader.FindAllStringSubmatchIndex(content, -1) {
+		h := header{match: m[:6]}
+		if content[m[2]:m[3]] == "{{$NEXT}}" {
+			h.unreleased = true
+		}
+		if m[4] >= 0 && !strings.ContainsAny(content[m[4]:m[5]], "0123456789") {
+			h.unreleased = true
+			h.match[4], h.match[5] = -1, -1
+		}
+		if m[6] >= 0 && m[6] < m[7] {
+			h.fields = map[string]string{"note": content[m[6]:m[7]]}
+		}
+		headers = append(headers, h)
+	}
+	return headers
+}
+
+// renderCPANHeader writes a CPAN::Changes release line. Unreleased
+// entries are written with the Dist::Zilla "{{$NEXT}}" placeholder.
+func renderCPANHeader(e Entry) string {
+	header := e.Version
+	if e.Unreleased {
+		header = "{{$NEXT}}"
+	}
+	if e.Date != nil {
+		header += " " + e.Date.Format("2006-01-02")
+	}
+	if note := e.Fields["note"]; note != "" {
+		header += " " + note
+	}
+	return header
+}
@@ -0,0 +1,73 @@
+package changelog
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCPANFormat(t *testing.T) {
+	p := Parse(mustReadFixture(t, "cpan_changes"))
+	if p.format != FormatCPAN {
+		t.Fatalf("detected format %d, want FormatCPAN", p.format)
+	}
+	if got := strings.Join(p.Versions(), ","); got != "{{$NEXT}},1.23,1.22_01,1.21" {
+		t.Fatalf("Versions() = %s", got)
+	}
+
+	t.Run("next release", func(t *testing.T) {
+		entry, ok := p.Unreleased()
+		if !ok || entry.Version != "{{$NEXT}}" || len(entry.Items) != 1 {
+			t.Errorf("Unreleased() = %+v, %v", entry, ok)
+		}
+	})
+
+	t.Run("groups", func(t *testing.T) {
+		entry, _ := p.Entry("1.23")
+		assertDate(t, entry.Date, 2024, time.March, 15)
+		if len(entry.Sections) != 2 {
+			t.Fatalf("Sections = %+v", entry.Sections)
+		}
+		fixed := entry.Sections[0]
+		if fixed.Name != "Bug Fixes" || fixed.Category != CategoryFixed || len(fixed.Items) != 2 {
+			t.Errorf("Sections[0] = %+v", fixed)
+		}
+		if len(fixed.Items[0].Children) != 1 {
+			t.Errorf("Items[0] = %+v", fixed.Items[0])
+		}
+		if entry.Sections[1].Name != "Enhancements" || len(entry.Sections[1].Items) != 1 {
+			t.Errorf("Sections[1] = %+v", entry.Sections[1])
+		}
+	})
+
+	t.Run("note", func(t *testing.T) {
+		entry, _ := p.Entry("1.22_01")
+		assertDate(t, entry.Date, 2024, time.February, 1)
+		if entry.Fields["note"] != "(TRIAL RELEASE)" {
+			t.Errorf("Fields = %v", entry.Fields)
+		}
+	})
+
+	t.Run("unknown date", func(t *testing.T) {
+		entry, _ := p.Entry("1.21")
+		if entry.Date != nil || entry.RawDate != "" || !entry.Unreleased {
+			t.Errorf("entry = %+v", entry)
+		}
+	})
+}
+
+func TestRenderCPAN(t *testing.T) {
+	date := time.Date(2024, time.March, 15, 0, 0, 0, 0, time.UTC)
+	out := Render(FormatCPAN, []Entry{{
+		Version:  "1.23",
+		Date:     &date,
+		Sections: []Section{{Name: "Bug Fixes", Items: []Item{{Text: "Fix", Children: []Item{{Text: "Detail"}}}}}},
+	}})
+	if want := "1.23 2024-03-15\n\n  [Bug Fixes]\n  - Fix\n    - Detail\n"; out != want {
+		t.Errorf("Render(FormatCPAN) = %q, want %q", out, want)
+	}
+	entry, ok := Parse(out).Entry("1.23")
+	if !ok || len(entry.Sections) != 1 || entry.Sections[0].Category != CategoryFixed {
+		t.Errorf("round trip = %+v", entry)
+	}
+}
@@ -0,0 +1,149 @@
+package changelog
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ISOWeek is a special layout value for ISO 8601 week dates such as
+// "2024-W11-5" (Friday of week 11) or "2024-W11" (its Monday). It can be
+// listed alongside Go time layouts in DefaultDateLayouts or
+// Parser.WithDateLayouts.
+const ISOWeek = "ISO 8601 week date"
+
+// DefaultDateLayouts are the layouts tried, in order, when parsing the date
+// in a version header. Ordinal suffixes ("15th") are removed and runs of
+// whitespace collapsed before matching, and month names match in any case.
+var DefaultDateLayouts = []string{
+	"2006-01-02",
+	"2006-1-2",
+	"2006/1/2",
+	"2006.1.2",
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02 Mon",
+	"2006-01-02 Mon 15:04",
+	ISOWeek,
+	"January 2, 2006",
+	"January 2 2006",
+	"Jan 2, 2006",
+	"Jan 2 2006",
+	"Jan. 2, 2006",
+	"Jan. 2 2006",
+	"2 January 2006",
+	"2 January, 2006",
+	"2 Jan 2006",
+	"2 Jan. 2006",
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"Mon, 2 Jan 2006",
+	"Monday, January 2, 2006",
+	"Mon Jan 2 2006",
+	"Mon Jan 2 15:04:05 MST 2006",
+	"Mon Jan 2 15:04:05 2006",
+}
+
+// dateExpr matches the date forms recognised in built-in header patterns.
+// It only needs to find the extent of a date; ParseDate decides whether
+// the text is valid.
+const dateExpr = `\d{4}-W\d{2}(?:-\d)?` +
+	`|\d{4}[-/.]\d{1,2}[-/.]\d{1,2}(?:[T ]\d{2}:\d{2}(?::\d{2})?(?:\.\d+)?(?:Z|[+-]\d{2}:?\d{2})?)?` +
+	`|(?i:(?:Mon|Tue|Wed|Thu|Fri|Sat|Sun)[a-z]*,?[ \t]+)?` +
+	`(?:\d{1,2}(?:st|nd|rd|th)?[ \t]+` + monthExpr + `,?[ \t]+\d{4}` +
+	`|` + monthExpr + `[ \t]+\d{1,2}(?:st|nd|rd|th)?,?[ \t]+\d{4})` +
+	`(?:[ \t]+\d{2}:\d{2}(?::\d{2})?(?:[ \t]+(?:[+-]\d{4}|[A-Z]{2,5}))?)?`
+
+const monthExpr = `(?i:Jan|Feb|Mar|Apr|May|Jun|Jul|Aug|Sep|Oct|Nov|Dec)[a-z]*\.?`
+
+var (
+	ordinalSuffix = regexp.MustCompile(`(?i)\b(\d{1,2})(?:st|nd|rd|th)\b`)
+	isoWeekDate   = regexp.MustCompile(`^(\d{4})-?W(\d{2})(?:-?([1-7]))?$`)
+)
+
+// ParseDate parses a header date using DefaultDateLayouts.
+func ParseDate(s string) (time.Time, bool) {
+	return ParseDateWithLayouts(s, DefaultDateLayouts)
+}
+
+// ParseDateWithLayouts parses a header date using the given layouts, which
+// are Go time layouts or ISOWeek.
+func ParseDateWithLayouts(s string, layouts []string) (time.Time, bool) {
+	t, _, ok := parseDate(s, layouts)
+	return t, ok
+}
+
+// parseDate also returns the layout that matched, so an edited date can be
+// written back in the same style.
+func parseDate(s string, layouts []string) (time.Time, string, bool) {
+	s = ordinalSuffix.ReplaceAllString(strings.Join(strings.Fields(s), " "), "$1")
+	for _, layout := range layouts {
+		if layout == ISOWeek {
+			if t, ok := parseISOWeek(s); ok {
+				return t, layout, true
+			}
+			continue
+		}
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, layout, true
+		}
+	}
+	return time.Time{}, "", false
+}
+
+// parseISOWeek converts an ISO 8601 week date to the calendar date of that
+// weekday. Week 1 is the week containing January 4th.
+func parseISOWeek(s string) (time.Time, bool) {
+	m := isoWeekDate.FindStringSubmatch(s)
+	if m == nil {
+		return time.Time{}, false
+	}
+	year, _ := strconv.Atoi(m[1])
+	week, _ := strconv.Atoi(m[2])
+	day := 1
+	if m[3] != "" {
+		day, _ = strconv.Atoi(m[3])
+	}
+	if week < 1 || week > 53 {
+		return time.Time{}, false
+	}
+
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, time.UTC)
+	weekday := int(jan4.Weekday()+6)%7 + 1 // Monday=1 ... Sunday=7
+	week1 := jan4.AddDate(0, 0, 1-weekday)
+	t := week1.AddDate(0, 0, (week-1)*7+day-1)
+	if y, w := t.ISOWeek(); y != year || w != week {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// formatDate renders t in the layout an existing date used, falling back
+// to YYYY-MM-DD for layouts that cannot be formatted.
+func formatDate(t time.Time, layout string) string {
+	if layout == "" || layout == ISOWeek {
+		return t.Format("2006-01-02")
+	}
+	return t.Format(layout)
+}
+
+// WithDateLayouts returns a parser for the same content that parses header
+// dates with layouts instead; p itself is not changed. Layouts are Go time
+// layouts or ISOWeek.
+func (p *Parser) WithDateLayouts(layouts ...string) *Parser {
+	c := p.clone()
+	c.dateLayouts = layouts
+	return c
+}
+
+func (p *Parser) layouts() []string {
+	if p.dateLayouts == nil {
+		return DefaultDateLayouts
+	}
+	return p.dateLayouts
+}
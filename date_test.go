@@ -0,0 +1,139 @@
+package changelog
+
+import (
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		input string
+		year  int
+		month time.Month
+		day   int
+	}{
+		{"2024-03-15", 2024, time.March, 15},
+		{"2024-3-5", 2024, time.March, 5},
+		{"2024/03/15", 2024, time.March, 15},
+		{"2024.03.15", 2024, time.March, 15},
+		{"2024-03-15T10:30:00Z", 2024, time.March, 15},
+		{"March 15, 2024", 2024, time.March, 15},
+		{"march 15 2024", 2024, time.March, 15},
+		{"Mar 15, 2024", 2024, time.March, 15},
+		{"Mar. 15, 2024", 2024, time.March, 15},
+		{"15 Mar 2024", 2024, time.March, 15},
+		{"15th March 2024", 2024, time.March, 15},
+		{"March 1st, 2024", 2024, time.March, 1},
+		{"Fri, 15 Mar 2024 10:30:00 +0000", 2024, time.March, 15},
+		{"Fri, 15 Mar 2024 10:30:00 GMT", 2024, time.March, 15},
+		{"2024-W11-5", 2024, time.March, 15},
+		{"2024-W11", 2024, time.March, 11},
+		{"2021-W01-1", 2021, time.January, 4},
+		{"2020-W53-7", 2021, time.January, 3},
+	}
+	for _, tt := range tests {
+		got, ok := ParseDate(tt.input)
+		if !ok {
+			t.Errorf("ParseDate(%q) failed", tt.input)
+			continue
+		}
+		assertDate(t, &got, tt.year, tt.month, tt.day)
+	}
+
+	for _, s := range []string{"", "soon", "2024-13-01", "2021-W53-1", "Smarch 15, 2024"} {
+		if _, ok := ParseDate(s); ok {
+			t.Errorf("ParseDate(%q) should fail", s)
+		}
+	}
+}
+
+func TestHeaderDates(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		raw     string
+	}{
+		{"markdown parens month name", "## 1.2.0 (March 15, 2024)\n\n- x\n", "March 15, 2024"},
+		{"markdown slash", "## 1.2.0 / 2024-03-15\n\n- x\n", "2024-03-15"},
+		{"markdown dash", "## 1.2.0 - 15 Mar 2024\n\n- x\n", "15 Mar 2024"},
+		{"markdown slashed date", "## v1.2.0 (2024/03/15)\n\n- x\n", "2024/03/15"},
+		{"markdown ordinal", "### 1.2.0 - March 15th, 2024\n\n- x\n", "March 15th, 2024"},
+		{"markdown rfc 2822", "## 1.2.0 (Fri, 15 Mar 2024 10:30:00 +0000)\n\n- x\n", "Fri, 15 Mar 2024 10:30:00 +0000"},
+		{"keep a changelog", "## [1.2.0] - 15 March 2024\n\n- x\n", "15 March 2024"},
+		{"keep a changelog iso week", "## [1.2.0] - 2024-W11-5\n\n- x\n", "2024-W11-5"},
+		{"underline", "1.2.0 - 15 Mar 2024\n===================\n\n- x\n", "15 Mar 2024"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, ok := Parse(tt.content).Entry("1.2.0")
+			if !ok {
+				t.Fatal("expected entry 1.2.0")
+			}
+			if entry.RawDate != tt.raw {
+				t.Errorf("RawDate = %q, want %q", entry.RawDate, tt.raw)
+			}
+			assertDate(t, entry.Date, 2024, time.March, 15)
+		})
+	}
+}
+
+func TestHeaderWithoutDate(t *testing.T) {
+	entry, _ := Parse("## 1.2.0 - Codename Bear\n\n- x\n").Entry("1.2.0")
+	if entry.Date != nil || entry.RawDate != "" {
+		t.Errorf("Date = %v, RawDate = %q", entry.Date, entry.RawDate)
+	}
+}
+
+func TestWithDateLayouts(t *testing.T) {
+	content := "Version 1.2.0 released 03/15/2024\n- x\n"
+	pattern := regexp.MustCompile(`^Version ([\d.]+) released (\S+)`)
+
+	entry, _ := ParseWithPattern(content, pattern).Entry("1.2.0")
+	if entry.Date != nil {
+		t.Errorf("expected no date with the default layouts, got %v", entry.Date)
+	}
+	if entry.RawDate != "03/15/2024" {
+		t.Errorf("RawDate = %q", entry.RawDate)
+	}
+
+	entry, _ = ParseWithPattern(content, pattern).WithDateLayouts("01/02/2006").Entry("1.2.0")
+	assertDate(t, entry.Date, 2024, time.March, 15)
+
+	t.Run("built-in formats", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			content string
+			layout  string
+		}{
+			{"markdown", "## 1.0.0 (15.03.2024)\n\n- x\n", "02.01.2006"},
+			{"keep a changelog", "## [1.0.0] - 15.03.2024\n\n- x\n", "02.01.2006"},
+			{"rdoc", "=== 1.0.0 / 15/03/2024\n\n* x\n", "02/01/2006"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				p := Parse(tt.content)
+				if entry, _ := p.Entry("1.0.0"); entry.Date != nil {
+					t.Fatalf("expected no date with the default layouts, got %v", entry.Date)
+				}
+				entry, _ := p.WithDateLayouts(tt.layout).Entry("1.0.0")
+				assertDate(t, entry.Date, 2024, time.March, 15)
+				if entry.Content != "- x" && entry.Content != "* x" {
+					t.Errorf("Content = %q", entry.Content)
+				}
+			})
+		}
+	})
+
+	t.Run("after first access", func(t *testing.T) {
+		p := Parse("## 1.0.0 (2024.15.03)\n\n- x\n")
+		if entry, _ := p.Entry("1.0.0"); entry.Date != nil {
+			t.Fatalf("expected no date with the default layouts, got %v", entry.Date)
+		}
+		entry, _ := p.WithDateLayouts("2006.02.01").Entry("1.0.0")
+		assertDate(t, entry.Date, 2024, time.March, 15)
+		if entry, _ := p.Entry("1.0.0"); entry.Date != nil {
+			t.Errorf("receiver changed: date = %v", entry.Date)
+		}
+	})
+}
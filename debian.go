@@ -0,0 +1,106 @@
+package changelog
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	debianHeader  = regexp.MustCompile(`(?m)^([a-z0-9][a-z0-9.+-]*)[ \t]+\(([^()\s]+)\)[ \t]+([^;\n]+);[ \t]*([^\n]*?)[ \t]*$`)
+	debianTrailer = regexp.MustCompile(`(?m)^ --[ \t]+(.+?<[^>\n]*>)[ \t]+(\S[^\n]*?)[ \t]*$`)
+)
+
+// debianHeaders finds the stanzas of a Debian changelog:
+//
+//	package (1:2.3-4) unstable; urgency=medium
+//
+//	  * Change.
+//
+//	 -- Maintainer <maintainer@example.com>  Fri, 15 Mar 2024 10:30:00 +0000
+//
+// The entry's content stops before the trailer line, whose date is used as
+// the entry's date. The distribution and the keyword=value pairs of the
+// header are kept as fields.
+func debianHeaders(content string) []header {
+	matches := debianHeader.FindAllStringSubmatchIndex(content, -1)
+	headers := make([]header, 0, len(matches))
+	for i, m := range matches {
+		next := len(content)
+		if i+1 < len(matches) {
+			next = matches[i+1][0]
+		}
+
+		h := header{
+			match: []int{m[0], m[1], m[4], m[5], -1, -1},
+			pkg:   content[m[2]:m[3]],
+			fields: map[string]string{
+				"distribution": strings.Join(strings.Fields(content[m[6]:m[7]]), " "),
+			},
+		}
+		for _, kv := range strings.Split(content[m[8]:m[9]], ",") {
+			key, value, ok := strings.Cut(kv, "=")
+			if ok {
+				h.fields[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(value)
+			}
+		}
+		h.unreleased = h.fields["distribution"] == "UNRELEASED"
+
+		if t := debianTrailer.FindStringSubmatchIndex(content[m[1]:next]); t != nil {
+			h.end = m[1] + t[0]
+			h.author = content[m[1]+t[2] : m[1]+t[3]]
+			h.match[4], h.match[5] = m[1]+t[4], m[1]+t[5]
+		} else {
+			h.end = next
+		}
+		headers = append(headers, h)
+	}
+	return headers
+}
+
+// looksLikeDebian reports whether content has Debian stanza headers and
+// trailers.
+func looksLikeDebian(content string) bool {
+	return debianHeader.MatchString(content) && debianTrailer.MatchString(content)
+}
+
+// renderDebianHeader writes the first line of a Debian stanza. The
+// package defaults to "unknown", the distribution to UNRELEASED for
+// unreleased entries and "unstable" otherwise, and the urgency to
+// "medium".
+func renderDebianHeader(e Entry) string {
+	pkg := e.Package
+	if pkg == "" {
+		pkg = "unknown"
+	}
+	dist := e.Fields["distribution"]
+	if dist == "" {
+		dist = "unstable"
+		if e.Unreleased {
+			dist = "UNRELEASED"
+		}
+	}
+	urgency := e.Fields["urgency"]
+	if urgency == "" {
+		urgency = "medium"
+	}
+	return pkg + " (" + e.Version + ") " + dist + "; urgency=" + urgency
+}
+
+// renderDebianTrailer writes the " -- author  date" line that closes a
+// Debian stanza, or nothing when the entry has neither. The line is only
+// recognised when the author is in "Name <email>" form; without one it is
+// written as it is and parses back as content.
+func renderDebianTrailer(e Entry) string {
+	if e.Author == "" && e.Date == nil {
+		return ""
+	}
+	trailer := " --"
+	if e.Author != "" {
+		trailer += " " + e.Author
+	}
+	if e.Date != nil {
+		trailer += "  " + e.Date.Format(time.RFC1123Z)
+	}
+	return trailer
+}
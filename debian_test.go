@@ -0,0 +1,123 @@
+package changelog
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDebianFormat(t *testing.T) {
+	p := Parse(mustReadFixture(t, "debian_changelog"))
+	if p.format != FormatDebian {
+		t.Fatalf("detected format %d, want FormatDebian", p.format)
+	}
+	if got := strings.Join(p.Versions(), ","); got != "1:2.3-5,1:2.3-4,2.3-1" {
+		t.Fatalf("Versions() = %s", got)
+	}
+
+	t.Run("stanza metadata", func(t *testing.T) {
+		entry, _ := p.Entry("1:2.3-4")
+		if entry.Package != "mytool" {
+			t.Errorf("Package = %q", entry.Package)
+		}
+		if entry.Author != "Jane Doe <jane@example.com>" {
+			t.Errorf("Author = %q", entry.Author)
+		}
+		if entry.Fields["distribution"] != "unstable" || entry.Fields["urgency"] != "medium" {
+			t.Errorf("Fields = %v", entry.Fields)
+		}
+		assertDate(t, entry.Date, 2024, time.March, 15)
+		if entry.RawDate != "Fri, 15 Mar 2024 10:30:00 +0000" {
+			t.Errorf("RawDate = %q", entry.RawDate)
+		}
+	})
+
+	t.Run("content excludes trailer", func(t *testing.T) {
+		entry, _ := p.Entry("1:2.3-4")
+		if strings.Contains(entry.Content, " -- ") || !strings.HasSuffix(entry.Content, "Update Standards-Version.") {
+			t.Errorf("Content = %q", entry.Content)
+		}
+	})
+
+	t.Run("contributor groups", func(t *testing.T) {
+		entry, _ := p.Entry("1:2.3-4")
+		if len(entry.Sections) != 2 || entry.Sections[0].Name != "Jane Doe" || entry.Sections[1].Name != "John Smith" {
+			t.Fatalf("Sections = %+v", entry.Sections)
+		}
+		items := entry.Sections[0].Items
+		if len(items) != 2 || items[1].Text != "Fix crash when the config file is empty. (Closes: #123456)" {
+			t.Errorf("Items = %+v", items)
+		}
+	})
+
+	t.Run("multiple keywords and distributions", func(t *testing.T) {
+		entry, _ := p.Entry("2.3-1")
+		if entry.Fields["distribution"] != "unstable experimental" || entry.Fields["binary-only"] != "yes" || entry.Fields["urgency"] != "high" {
+			t.Errorf("Fields = %v", entry.Fields)
+		}
+		if len(entry.Items) != 1 || len(entry.Items[0].Children) != 1 {
+			t.Errorf("Items = %+v", entry.Items)
+		}
+	})
+
+	t.Run("unreleased distribution", func(t *testing.T) {
+		if got := strings.Join(p.Released(), ","); got != "1:2.3-4,2.3-1" {
+			t.Errorf("Released() = %s", got)
+		}
+	})
+
+	t.Run("debian scheme by default", func(t *testing.T) {
+		entries, err := p.Query("<1:2.3-5")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, e := range entries {
+			got = append(got, e.Version)
+		}
+		if strings.Join(got, ",") != "1:2.3-4,2.3-1" {
+			t.Errorf("Query() = %v", got)
+		}
+	})
+}
+
+func TestRenderDebian(t *testing.T) {
+	date := time.Date(2024, time.March, 15, 10, 30, 0, 0, time.UTC)
+	entries := []Entry{{
+		Version: "1.0-1",
+		Date:    &date,
+		Package: "mytool",
+		Author:  "Jane Doe <jane@example.com>",
+		Items:   []Item{{Text: "Initial release.", Children: []Item{{Text: "Detail."}}}},
+	}}
+	out := Render(FormatDebian, entries)
+	want := "mytool (1.0-1) unstable; urgency=medium\n\n  * Initial release.\n    * Detail.\n\n" +
+		" -- Jane Doe <jane@example.com>  Fri, 15 Mar 2024 10:30:00 +0000\n"
+	if out != want {
+		t.Fatalf("Render() = %q, want %q", out, want)
+	}
+
+	entry, ok := Parse(out).Entry("1.0-1")
+	if !ok || entry.Author != "Jane Doe <jane@example.com>" || len(entry.Items) != 1 {
+		t.Errorf("round trip = %+v", entry)
+	}
+	assertDate(t, entry.Date, 2024, time.March, 15)
+}
+
+func TestEditorDebian(t *testing.T) {
+	e := NewEditor(mustReadFixture(t, "debian_changelog"))
+	if err := e.AddItem("1:2.3-5", "", "Another change."); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(e.String(), "  * Pending change.\n  * Another change.\n\n -- Jane") {
+		t.Errorf("got:\n%s", e.String())
+	}
+
+	date := time.Date(2024, time.April, 2, 8, 0, 0, 0, time.UTC)
+	if err := e.SetDate("1:2.3-5", date); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(e.String(), "<jane@example.com>  Tue, 02 Apr 2024 08:00:00 +0000\n\nmytool (1:2.3-4)") {
+		t.Errorf("got:\n%s", e.String())
+	}
+}
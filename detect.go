@@ -0,0 +1,166 @@
+package changelog
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Candidate is a format considered by DetectFormat, with its score and
+// the observations behind it.
+type Candidate struct {
+	Format  Format
+	Score   float64
+	Reasons []string
+}
+
+// detectSize is how much of the content DetectFormat scores at first.
+var detectSize = 64 << 10
+
+// plausibleVersion matches version strings in any common scheme, as
+// opposed to link text or prose caught by a header pattern.
+var plausibleVersion = regexp.MustCompile(`^[vV]?\d[\w.+~:-]*$`)
+
+// DetectFormat scores every registered format against content and
+// returns them best first; formats with equal scores keep their
+// registration order, built-ins first. Parse uses the first candidate,
+// or FormatMarkdown when no candidate scores above zero.
+//
+// A format scores zero unless its Detect method reports some confidence.
+// Otherwise the score counts the headers it finds whose versions are
+// plausible (or that are marked unreleased), weighted by how many of
+// those headers have dates that parse and by how consistently the
+// versions are ordered, newest first or oldest first, and scaled by the
+// format's confidence. Reasons explain each of these in words, which helps
+// when a file parses to fewer versions than expected.
+//
+// Only the first 64 KiB or so are scored, to the end of a line, which is
+// plenty to tell formats apart and keeps Parse fast on large files. When no
+// format scores above zero there, twice as much is scored, and so on up to
+// the whole content, so a long preamble does not hide the headers.
+func DetectFormat(content string) []Candidate {
+	formats.RLock()
+	handlers := slices.Clone(formats.handlers)
+	formats.RUnlock()
+
+	for size := detectSize; ; size *= 2 {
+		prefix := content
+		if size < len(content) {
+			prefix = content[:nextLine(content, size)]
+		}
+		var candidates []Candidate
+		for i, h := range handlers {
+			if h == nil {
+				continue
+			}
+			candidates = append(candidates, scoreFormat(Format(i), h, prefix))
+		}
+		slices.SortStableFunc(candidates, func(a, b Candidate) int {
+			return cmp.Compare(b.Score, a.Score)
+		})
+		if len(prefix) == len(content) || (len(candidates) > 0 && candidates[0].Score > 0) {
+			return candidates
+		}
+	}
+}
+
+// detectFormat returns the best candidate for content, falling back to
+// FormatMarkdown.
+func detectFormat(content string) Format {
+	if candidates := DetectFormat(content); len(candidates) > 0 && candidates[0].Score > 0 {
+		return candidates[0].Format
+	}
+	return FormatMarkdown
+}
+
+func scoreFormat(format Format, h FormatHandler, content string) Candidate {
+	c := Candidate{Format: format}
+	confidence := h.Detect(content)
+	if confidence <= 0 {
+		c.Reasons = append(c.Reasons, "format markers not found")
+		return c
+	}
+	c.Reasons = append(c.Reasons, fmt.Sprintf("format markers found (confidence %.2f)", confidence))
+
+	p := &Parser{content: content, format: format, matchGroup: 1}
+	if f, ok := h.(*builtinFormat); ok {
+		p.pattern = f.pattern
+	}
+	headers := p.findHeaders()
+	if len(headers) == 0 {
+		c.Reasons = append(c.Reasons, "no version headers found")
+		return c
+	}
+
+	var plausible, dated, parsed int
+	var released []string
+	for _, hd := range headers {
+		_, version := p.headerVersion(hd)
+		unreleased := hd.unreleased || strings.EqualFold(version, "unreleased")
+		if !unreleased && !plausibleVersion.MatchString(version) {
+			continue
+		}
+		plausible++
+		if date, raw := p.extractDate(hd.match); raw != "" {
+			dated++
+			if date != nil {
+				parsed++
+			}
+		}
+		if !unreleased {
+			released = append(released, version)
+		}
+	}
+	c.Reasons = append(c.Reasons, fmt.Sprintf("%d of %d headers have plausible versions", plausible, len(headers)))
+	if plausible == 0 {
+		return c
+	}
+
+	dates := float64(parsed) / float64(plausible)
+	switch {
+	case dated == 0:
+		c.Reasons = append(c.Reasons, "no dates found")
+	case parsed == dated:
+		c.Reasons = append(c.Reasons, fmt.Sprintf("%d of %d headers have dates, all parsed", dated, plausible))
+	default:
+		c.Reasons = append(c.Reasons, fmt.Sprintf("%d of %d headers have dates, %d parsed", dated, plausible, parsed))
+	}
+
+	order, reason := versionOrder(released, p.versionScheme())
+	c.Reasons = append(c.Reasons, reason)
+
+	c.Score = float64(plausible) * (0.5 + 0.25*dates + 0.25*order) * (0.5 + 0.5*min(confidence, 1))
+	return c
+}
+
+// versionOrder measures how consistently versions, in file order, are
+// sorted in one direction, as the fraction of adjacent comparable pairs
+// that follow the majority direction. With no pairs to compare it is 0.5.
+func versionOrder(versions []string, scheme Scheme) (float64, string) {
+	var valid []string
+	for _, v := range versions {
+		if scheme.Valid(v) {
+			valid = append(valid, v)
+		}
+	}
+	var desc, asc int
+	for i := 0; i+1 < len(valid); i++ {
+		switch scheme.Compare(valid[i], valid[i+1]) {
+		case 1:
+			desc++
+		case -1:
+			asc++
+		}
+	}
+	pairs := desc + asc
+	if pairs == 0 {
+		return 0.5, fmt.Sprintf("no %s versions to compare", scheme.Name())
+	}
+	direction, n := "newest first", desc
+	if asc > desc {
+		direction, n = "oldest first", asc
+	}
+	return float64(n) / float64(pairs), fmt.Sprintf("%d of %d %s version pairs ordered %s", n, pairs, scheme.Name(), direction)
+}
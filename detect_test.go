@@ -0,0 +1,112 @@
+package changelog
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDetectFormat(t *testing.T) {
+	t.Run("stray link heading", func(t *testing.T) {
+		content := "# Changelog\n\n## [Docs](https://example.com/docs)\n\n" +
+			"## 1.2.0 (2024-03-01)\n\n- Add.\n\n## 1.1.0 (2024-02-01)\n\n- Fix.\n\n## 1.0.0 (2024-01-01)\n\n- First.\n"
+		if got := Parse(content).Versions(); strings.Join(got, ",") != "1.2.0,1.1.0,1.0.0" {
+			t.Errorf("Versions() = %v", got)
+		}
+
+		candidates := DetectFormat(content)
+		if candidates[0].Format != FormatMarkdown {
+			t.Fatalf("best candidate = %v", candidates[0].Format)
+		}
+		kac := findCandidate(t, candidates, FormatKeepAChangelog)
+		if kac.Score != 0 || !slices.Contains(kac.Reasons, "0 of 1 headers have plausible versions") {
+			t.Errorf("keep-a-changelog = %+v", kac)
+		}
+	})
+
+	t.Run("report", func(t *testing.T) {
+		content := "## 2.0.0 (2024-03-01)\n\n## 1.0.0 (someday)\n\n## 1.5.0\n"
+		md := findCandidate(t, DetectFormat(content), FormatMarkdown)
+		want := []string{
+			"format markers found (confidence 0.10)",
+			"3 of 3 headers have plausible versions",
+			"1 of 3 headers have dates, all parsed",
+			"1 of 2 semver version pairs ordered newest first",
+		}
+		if strings.Join(md.Reasons, "\n") != strings.Join(want, "\n") {
+			t.Errorf("Reasons = %q", md.Reasons)
+		}
+		if md.Score <= 0 {
+			t.Errorf("Score = %v", md.Score)
+		}
+		rpm := findCandidate(t, DetectFormat(content), FormatRPM)
+		if rpm.Score != 0 || rpm.Reasons[0] != "format markers not found" {
+			t.Errorf("rpm = %+v", rpm)
+		}
+	})
+
+	t.Run("sorted by score", func(t *testing.T) {
+		candidates := DetectFormat(mustReadFixture(t, "changes.rst"))
+		if candidates[0].Format != FormatRST {
+			t.Errorf("best candidate = %v", candidates[0].Format)
+		}
+		for i := 1; i < len(candidates); i++ {
+			if candidates[i].Score > candidates[i-1].Score {
+				t.Errorf("candidates not sorted: %+v", candidates)
+			}
+		}
+	})
+
+	t.Run("nothing matches", func(t *testing.T) {
+		if p := Parse("Just some text.\n"); p.format != FormatMarkdown {
+			t.Errorf("format = %v", p.format)
+		}
+	})
+}
+
+func TestVersionOrder(t *testing.T) {
+	tests := []struct {
+		versions []string
+		want     float64
+	}{
+		{[]string{"3.0.0", "2.0.0", "1.0.0"}, 1},
+		{[]string{"1.0.0", "2.0.0", "3.0.0"}, 1},
+		{[]string{"3.0.0", "1.0.0", "2.0.0"}, 0.5},
+		{[]string{"1.0.0"}, 0.5},
+		{[]string{"x", "y"}, 0.5},
+	}
+	for _, tt := range tests {
+		if got, _ := versionOrder(tt.versions, SemVer); got != tt.want {
+			t.Errorf("versionOrder(%v) = %v, want %v", tt.versions, got, tt.want)
+		}
+	}
+}
+
+func findCandidate(t *testing.T, candidates []Candidate, f Format) Candidate {
+	t.Helper()
+	for _, c := range candidates {
+		if c.Format == f {
+			return c
+		}
+	}
+	t.Fatalf("no candidate for %v", f)
+	return Candidate{}
+}
+
+func TestDetectFormatLongPreamble(t *testing.T) {
+	content := strings.Repeat("Some prose about the project.\n", 5000) +
+		"## [1.1.0] - 2024-03-15\n\n- b\n\n## [1.0.0] - 2024-01-15\n\n- a\n"
+	if len(content) <= detectSize {
+		t.Fatalf("preamble of %d bytes is not longer than detectSize", len(content))
+	}
+	if got := DetectFormat(content)[0].Format; got != FormatKeepAChangelog {
+		t.Errorf("DetectFormat() = %v", got)
+	}
+}
+
+func BenchmarkDetectFormat(b *testing.B) {
+	content := largeChangelog(20000)
+	for b.Loop() {
+		DetectFormat(content)
+	}
+}
@@ -0,0 +1,324 @@
+package changelog
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Editor applies targeted edits to existing changelog content. Each edit
+// splices new text into the content at the offsets the parser matched, so
+// every byte outside the edited region (preamble, link reference
+// definitions, unusual whitespace) is left untouched. Inserted lines use
+// the line ending of the file, "\n" or "\r\n".
+type Editor struct {
+	content string
+	format  Format
+	pattern *regexp.Regexp
+	newline string
+}
+
+// NewEditor creates an editor for content with automatic format detection.
+// The detected format is kept for the lifetime of the editor.
+func NewEditor(content string) *Editor {
+	return NewEditorWithFormat(content, FormatAuto)
+}
+
+// NewEditorWithFormat creates an editor for content in the given format.
+func NewEditorWithFormat(content string, format Format) *Editor {
+	p := ParseWithFormat(content, format)
+	return &Editor{content: content, format: p.format, pattern: p.pattern, newline: lineEnding(content)}
+}
+
+// String returns the edited content.
+func (e *Editor) String() string {
+	return e.content
+}
+
+// Parser returns a parser over the current content.
+func (e *Editor) Parser() *Parser {
+	return &Parser{
+		content:    e.content,
+		format:     e.format,
+		pattern:    e.pattern,
+		matchGroup: 1,
+	}
+}
+
+// InsertVersion renders entry in the changelog's format and inserts it
+// above the newest released version, below any Unreleased section. It
+// returns an error if the version already exists.
+func (e *Editor) InsertVersion(entry Entry) error {
+	p := e.Parser()
+	if _, ok := p.Entry(entry.Version); ok {
+		return fmt.Errorf("version %s already exists", entry.Version)
+	}
+	if entry.Package == "" && len(p.entries) > 0 {
+		entry.Package = p.entries[0].entry.Package
+	}
+	block := e.restyle(p, renderEntry(e.format, entry))
+
+	for _, ve := range p.entries {
+		if ve.entry.Unreleased {
+			continue
+		}
+		e.splice(ve.match[0], ve.match[0], block+"\n\n")
+		return nil
+	}
+
+	end := len(e.content)
+	if n := len(p.entries); n > 0 {
+		end = p.entries[n-1].end
+	}
+	e.appendBlock(end, block)
+	return nil
+}
+
+// AddItem appends a bullet with the given text to a section of an existing
+// version. The section is matched by heading name or category, case
+// insensitively, and is created at the end of the entry if missing. An
+// empty section adds the item to the entry's own list, for entries that
+// are not split into sections. The new bullet copies the indentation and
+// marker of the list it joins.
+func (e *Editor) AddItem(version, section, text string) error {
+	p := e.Parser()
+	ve, ok := p.lookup(version)
+	if !ok {
+		return fmt.Errorf("version %s not found", version)
+	}
+
+	var items []Item
+	var target *Section
+	if section != "" {
+		for i, s := range ve.entry.Sections {
+			if strings.EqualFold(s.Name, section) || (s.Category != CategoryUnknown && s.Category == ParseCategory(section)) {
+				target = &ve.entry.Sections[i]
+				break
+			}
+		}
+		if target == nil {
+			e.appendBlock(ve.end, e.restyle(p, renderSection(e.format, Section{Name: section, Items: []Item{{Text: text}}})))
+			return nil
+		}
+		items = target.Items
+	} else {
+		items = ve.entry.Items
+	}
+
+	offsets := lineOffsets(e.content)
+	if len(items) > 0 {
+		last := items[len(items)-1]
+		prefix := "- "
+		if m := bulletLine.FindStringSubmatch(lineAt(e.content, offsets, last.StartLine)); m != nil {
+			prefix = m[1] + string(m[0][len(m[1])]) + " "
+		}
+		e.insertLine(offsets, last.EndLine+1, prefix+text)
+		return nil
+	}
+
+	if target != nil {
+		e.appendBlock(lineEndOffset(e.content, offsets, target.EndLine), renderItems(e.format, []Item{{Text: text}}))
+		return nil
+	}
+	e.appendBlock(ve.end, renderItems(e.format, []Item{{Text: text}}))
+	return nil
+}
+
+// SetDate sets or replaces the release date in a version's header. An
+// existing date is replaced in place, keeping its layout when it is one of
+// DefaultDateLayouts; otherwise the date is added in the style of the
+// changelog's format.
+func (e *Editor) SetDate(version string, date time.Time) error {
+	p := e.Parser()
+	ve, ok := p.lookup(version)
+	if !ok {
+		return fmt.Errorf("version %s not found", version)
+	}
+	formatted := date.Format("2006-01-02")
+	m := ve.match
+
+	if len(m) > 5 && m[4] >= 0 {
+		_, layout, _ := parseDate(ve.entry.RawDate, p.layouts())
+		e.splice(m[4], m[5], formatDate(date, layout))
+		e.fitAdornment(m[4])
+		return nil
+	}
+
+	switch e.format {
+	case FormatKeepAChangelog:
+		// The version group sits inside the brackets, which may be
+		// followed by a link.
+		at := m[3] + 1
+		at += len(inlineLink.FindString(e.content[at:m[1]]))
+		e.splice(at, at, " - "+formatted)
+	case FormatMarkdown, FormatUnderline, FormatRST:
+		e.splice(m[3], m[3], " ("+formatted+")")
+		e.fitAdornment(m[3])
+	case FormatRDoc:
+		e.splice(m[3], m[3], " / "+formatted)
+	case FormatCPAN:
+		e.splice(m[3], m[3], " "+formatted)
+	case FormatCabal:
+		// The header match ends after any brackets and link.
+		e.splice(m[1], m[1], " -- "+formatted)
+	case FormatAsciiDoc:
+		e.splice(m[1], m[1], " ("+formatted+")")
+	case FormatOrg:
+		e.splice(m[3], m[3], " "+renderOrgDate(date))
+	case FormatDebian:
+		return fmt.Errorf("cannot add a date to version %s: stanza has no trailer line", version)
+	default:
+		return fmt.Errorf("cannot add a date to version %s: pattern has no date group", version)
+	}
+	return nil
+}
+
+// Release promotes the Unreleased section to version with the given date
+// and leaves a fresh, empty Unreleased heading above it. When the file has
+// an "[Unreleased]: .../compare/<tag>...HEAD" link reference definition, it
+// is moved on to the new tag and a "[version]: .../compare/<tag>...<new>"
+// definition is added below it. New tags reuse the prefix (such as "v") of
+// the previous tag. Debian changelogs are not supported.
+func (e *Editor) Release(version string, date time.Time) error {
+	if e.format == FormatDebian {
+		return fmt.Errorf("release is not supported for Debian changelogs")
+	}
+	p := e.Parser()
+	unreleased, ok := p.findUnreleased()
+	if !ok {
+		return fmt.Errorf("no Unreleased section found")
+	}
+	if _, ok := p.lookup(version); ok {
+		return fmt.Errorf("version %s already exists", version)
+	}
+
+	var previous string
+	for _, ve := range p.entries {
+		if !ve.entry.Unreleased {
+			previous = ve.version
+			break
+		}
+	}
+
+	at := len(strings.TrimSuffix(e.content[:lineEnd(e.content, unreleased.match[1])], "\r"))
+	e.splice(at, at, "\n\n"+e.restyle(p, renderHeader(e.format, Entry{Version: version, Date: &date})))
+	e.updateCompareLinks(previous, version)
+	return nil
+}
+
+var (
+	unreleasedLink = regexp.MustCompile(`(?mi)^\[unreleased\]:[ \t]*(\S+?)[ \t]*\r?$`)
+	compareURL     = regexp.MustCompile(`^(.*/compare/)(.+)\.\.\.HEAD$`)
+)
+
+func (e *Editor) updateCompareLinks(previous, version string) {
+	m := unreleasedLink.FindStringSubmatchIndex(e.content)
+	if m == nil {
+		return
+	}
+	parts := compareURL.FindStringSubmatch(e.content[m[2]:m[3]])
+	if parts == nil {
+		return
+	}
+	base, prevTag := parts[1], parts[2]
+
+	prefix := ""
+	if previous != "" && strings.HasSuffix(prevTag, previous) {
+		prefix = strings.TrimSuffix(prevTag, previous)
+	} else if strings.HasPrefix(prevTag, "v") && !strings.HasPrefix(version, "v") {
+		prefix = "v"
+	}
+	tag := prefix + version
+
+	e.splice(m[2], m[3], base+tag+"...HEAD")
+	end := len(strings.TrimSuffix(e.content[:m[1]+len(tag)-len(prevTag)], "\r"))
+	e.splice(end, end, "\n["+version+"]: "+base+prevTag+"..."+tag)
+}
+
+// restyle adapts text rendered in the editor's format to the conventions
+// of the file, such as the adornment characters of an RST changelog.
+func (e *Editor) restyle(p *Parser, text string) string {
+	if e.format != FormatRST {
+		return text
+	}
+	return p.rstAdornments().restyle(text)
+}
+
+// fitAdornment keeps the adornments of an edited RST title at least as
+// long as the title.
+func (e *Editor) fitAdornment(offset int) {
+	if e.format == FormatRST {
+		e.content = fitRSTAdornment(e.content, offset)
+	}
+}
+
+func (e *Editor) splice(start, end int, text string) {
+	if e.newline == "\r\n" {
+		text = strings.ReplaceAll(text, "\n", "\r\n")
+	}
+	e.content = e.content[:start] + text + e.content[end:]
+}
+
+// lineEnding returns the line ending used by the first line of content.
+func lineEnding(content string) string {
+	if i := strings.IndexByte(content, '\n'); i > 0 && content[i-1] == '\r' {
+		return "\r\n"
+	}
+	return "\n"
+}
+
+// appendBlock inserts block after the last non-blank byte before end,
+// separated from it by a blank line. Whitespace that followed that byte is
+// kept after the block.
+func (e *Editor) appendBlock(end int, block string) {
+	at := len(strings.TrimRight(e.content[:end], " \t\r\n"))
+	if at == 0 {
+		e.splice(0, 0, block+"\n")
+		return
+	}
+	if at == len(e.content) {
+		block += "\n"
+	}
+	e.splice(at, at, "\n\n"+block)
+}
+
+// insertLine inserts text as a new line before line number line.
+func (e *Editor) insertLine(offsets []int, line int, text string) {
+	if line < len(offsets) {
+		e.splice(offsets[line], offsets[line], text+"\n")
+		return
+	}
+	if strings.HasSuffix(e.content, "\n") {
+		e.splice(len(e.content), len(e.content), text+"\n")
+		return
+	}
+	e.splice(len(e.content), len(e.content), "\n"+text)
+}
+
+// lineOffsets returns the byte offset at which each line of s starts.
+func lineOffsets(s string) []int {
+	offsets := []int{0}
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\n' && i+1 < len(s) {
+			offsets = append(offsets, i+1)
+		}
+	}
+	return offsets
+}
+
+func lineAt(s string, offsets []int, line int) string {
+	if line >= len(offsets) {
+		return ""
+	}
+	return s[offsets[line]:lineEndOffset(s, offsets, line)]
+}
+
+// lineEndOffset returns the offset of the newline ending the given line,
+// or the end of s for the last line.
+func lineEndOffset(s string, offsets []int, line int) int {
+	if line+1 < len(offsets) {
+		return offsets[line+1] - 1
+	}
+	return len(strings.TrimSuffix(s, "\n"))
+}
@@ -0,0 +1,306 @@
+package changelog
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const editorFixture = `# Changelog
+
+Preamble   with odd   spacing.
+
+## [Unreleased]
+
+### Added
+
+* Pending thing
+
+## [1.0.0] - 2024-01-15
+### Fixed
+- Bug one
+  - detail
+
+
+[Unreleased]: https://example.com/compare/v1.0.0...HEAD
+[1.0.0]: https://example.com/releases/v1.0.0
+`
+
+func TestEditorInsertVersion(t *testing.T) {
+	e := NewEditor(editorFixture)
+	date := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	err := e.InsertVersion(Entry{
+		Version:  "1.1.0",
+		Date:     &date,
+		Sections: []Section{{Category: CategoryAdded, Items: []Item{{Text: "Shiny"}}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := strings.Replace(editorFixture, "## [1.0.0] - 2024-01-15\n",
+		"## [1.1.0] - 2024-03-01\n\n### Added\n\n- Shiny\n\n## [1.0.0] - 2024-01-15\n", 1)
+	if e.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", e.String(), want)
+	}
+
+	versions := e.Parser().Versions()
+	if strings.Join(versions, ",") != "Unreleased,1.1.0,1.0.0" {
+		t.Errorf("versions = %v", versions)
+	}
+
+	if err := e.InsertVersion(Entry{Version: "1.0.0"}); err == nil {
+		t.Error("expected error inserting an existing version")
+	}
+}
+
+func TestEditorInsertVersionEmpty(t *testing.T) {
+	e := NewEditorWithFormat("# Changelog\n", FormatMarkdown)
+	if err := e.InsertVersion(Entry{Version: "0.1.0", Items: []Item{{Text: "First"}}}); err != nil {
+		t.Fatal(err)
+	}
+	want := "# Changelog\n\n## 0.1.0\n\n- First\n"
+	if e.String() != want {
+		t.Errorf("got %q, want %q", e.String(), want)
+	}
+}
+
+func TestEditorAddItem(t *testing.T) {
+	t.Run("existing section copies marker", func(t *testing.T) {
+		e := NewEditor(editorFixture)
+		if err := e.AddItem("Unreleased", "added", "Another thing"); err != nil {
+			t.Fatal(err)
+		}
+		want := strings.Replace(editorFixture, "* Pending thing\n", "* Pending thing\n* Another thing\n", 1)
+		if e.String() != want {
+			t.Errorf("got:\n%s", e.String())
+		}
+	})
+
+	t.Run("after nested children", func(t *testing.T) {
+		e := NewEditor(editorFixture)
+		if err := e.AddItem("1.0.0", "Fixed", "Bug two"); err != nil {
+			t.Fatal(err)
+		}
+		want := strings.Replace(editorFixture, "  - detail\n", "  - detail\n- Bug two\n", 1)
+		if e.String() != want {
+			t.Errorf("got:\n%s", e.String())
+		}
+		entry, _ := e.Parser().Entry("1.0.0")
+		if len(entry.Items) != 2 || entry.Items[1].Text != "Bug two" {
+			t.Errorf("items = %+v", entry.Items)
+		}
+	})
+
+	t.Run("new section", func(t *testing.T) {
+		e := NewEditor(editorFixture)
+		if err := e.AddItem("Unreleased", "Security", "Patched"); err != nil {
+			t.Fatal(err)
+		}
+		want := strings.Replace(editorFixture, "* Pending thing\n", "* Pending thing\n\n### Security\n\n- Patched\n", 1)
+		if e.String() != want {
+			t.Errorf("got:\n%s", e.String())
+		}
+		entry, _ := e.Parser().Entry("Unreleased")
+		if s, ok := entry.Section(CategorySecurity); !ok || s.Items[0].Text != "Patched" {
+			t.Errorf("sections = %+v", entry.Sections)
+		}
+	})
+
+	t.Run("entry without sections", func(t *testing.T) {
+		e := NewEditorWithFormat("## 1.0.0\n\n- One", FormatMarkdown)
+		if err := e.AddItem("1.0.0", "", "Two"); err != nil {
+			t.Fatal(err)
+		}
+		if e.String() != "## 1.0.0\n\n- One\n- Two" {
+			t.Errorf("got %q", e.String())
+		}
+	})
+
+	t.Run("unknown version", func(t *testing.T) {
+		e := NewEditor(editorFixture)
+		if err := e.AddItem("9.9.9", "Added", "x"); err == nil {
+			t.Error("expected error")
+		}
+		if e.String() != editorFixture {
+			t.Error("content changed after failed edit")
+		}
+	})
+}
+
+func TestEditorAddSectionBeforeLinks(t *testing.T) {
+	e := NewEditor(editorFixture)
+	if err := e.AddItem("1.0.0", "Security", "Patched"); err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Replace(editorFixture, "  - detail\n", "  - detail\n\n### Security\n\n- Patched\n", 1)
+	if e.String() != want {
+		t.Errorf("got:\n%s", e.String())
+	}
+}
+
+func TestEditorCRLF(t *testing.T) {
+	date := time.Date(2024, time.May, 10, 0, 0, 0, 0, time.UTC)
+	edit := func(e *Editor) error {
+		return errors.Join(
+			e.AddItem("Unreleased", "Added", "Another thing"),
+			e.AddItem("Unreleased", "Security", "Patched"),
+			e.AddItem("1.0.0", "Fixed", "Bug two"),
+			e.InsertVersion(Entry{Version: "0.9.0", Sections: []Section{{Category: CategoryAdded, Items: []Item{{Text: "Shiny"}}}}}),
+			e.Release("1.1.0", date),
+		)
+	}
+
+	lf := NewEditor(editorFixture)
+	crlf := NewEditor(strings.ReplaceAll(editorFixture, "\n", "\r\n"))
+	if err := errors.Join(edit(lf), edit(crlf)); err != nil {
+		t.Fatal(err)
+	}
+	want := strings.ReplaceAll(lf.String(), "\n", "\r\n")
+	if crlf.String() != want {
+		t.Errorf("got:\n%q\nwant:\n%q", crlf.String(), want)
+	}
+}
+
+func TestEditorSetDate(t *testing.T) {
+	date := time.Date(2024, time.April, 2, 0, 0, 0, 0, time.UTC)
+
+	t.Run("replace existing date", func(t *testing.T) {
+		e := NewEditor(editorFixture)
+		if err := e.SetDate("1.0.0", date); err != nil {
+			t.Fatal(err)
+		}
+		want := strings.Replace(editorFixture, "2024-01-15", "2024-04-02", 1)
+		if e.String() != want {
+			t.Errorf("got:\n%s", e.String())
+		}
+	})
+
+	t.Run("keep existing layout", func(t *testing.T) {
+		e := NewEditor("## 1.0.0 (January 15, 2024)\n\n- x\n")
+		if err := e.SetDate("1.0.0", date); err != nil {
+			t.Fatal(err)
+		}
+		if want := "## 1.0.0 (April 2, 2024)\n\n- x\n"; e.String() != want {
+			t.Errorf("got %q, want %q", e.String(), want)
+		}
+	})
+
+	tests := []struct {
+		name    string
+		format  Format
+		content string
+		want    string
+	}{
+		{"keep a changelog", FormatKeepAChangelog, "## [1.0.0]\n\n- x\n", "## [1.0.0] - 2024-04-02\n\n- x\n"},
+		{"markdown", FormatMarkdown, "## v1.0.0\n\n- x\n", "## v1.0.0 (2024-04-02)\n\n- x\n"},
+		{"underline", FormatUnderline, "1.0.0\n=====\n\n- x\n", "1.0.0 (2024-04-02)\n=====\n\n- x\n"},
+		{"rdoc", FormatRDoc, "=== 1.0.0\n\n* x\n", "=== 1.0.0 / 2024-04-02\n\n* x\n"},
+		{"cpan", FormatCPAN, "1.0.0 (TRIAL)\n  - x\n", "1.0.0 2024-04-02 (TRIAL)\n  - x\n"},
+		{"cabal", FormatCabal, "## [1.0.0](https://example.com)\n\n* x\n", "## [1.0.0](https://example.com) -- 2024-04-02\n\n* x\n"},
+		{"asciidoc", FormatAsciiDoc, "== https://example.com[1.0.0]\n\n* x\n", "== https://example.com[1.0.0] (2024-04-02)\n\n* x\n"},
+		{"org", FormatOrg, "* 1.0.0 :release:\n- x\n", "* 1.0.0 <2024-04-02 Tue> :release:\n- x\n"},
+		{"org replace", FormatOrg, "* 1.0.0 <2023-01-05 Thu>\n- x\n", "* 1.0.0 <2024-04-02 Tue>\n- x\n"},
+	}
+	for _, tt := range tests {
+		t.Run("add date "+tt.name, func(t *testing.T) {
+			e := NewEditorWithFormat(tt.content, tt.format)
+			if err := e.SetDate("1.0.0", date); err != nil {
+				t.Fatal(err)
+			}
+			if e.String() != tt.want {
+				t.Errorf("got %q, want %q", e.String(), tt.want)
+			}
+			entry, _ := e.Parser().Entry("1.0.0")
+			assertDate(t, entry.Date, 2024, time.April, 2)
+		})
+	}
+}
+
+func TestEditorRelease(t *testing.T) {
+	date := time.Date(2024, time.May, 10, 0, 0, 0, 0, time.UTC)
+
+	t.Run("promotes unreleased and updates links", func(t *testing.T) {
+		e := NewEditor(editorFixture)
+		if err := e.Release("1.1.0", date); err != nil {
+			t.Fatal(err)
+		}
+		want := strings.NewReplacer(
+			"## [Unreleased]\n", "## [Unreleased]\n\n## [1.1.0] - 2024-05-10\n",
+			"[Unreleased]: https://example.com/compare/v1.0.0...HEAD\n",
+			"[Unreleased]: https://example.com/compare/v1.1.0...HEAD\n[1.1.0]: https://example.com/compare/v1.0.0...v1.1.0\n",
+		).Replace(editorFixture)
+		if e.String() != want {
+			t.Errorf("got:\n%s\nwant:\n%s", e.String(), want)
+		}
+
+		p := e.Parser()
+		if strings.Join(p.Versions(), ",") != "Unreleased,1.1.0,1.0.0" {
+			t.Errorf("versions = %v", p.Versions())
+		}
+		unreleased, _ := p.Entry("Unreleased")
+		if unreleased.Content != "" {
+			t.Errorf("expected empty Unreleased, got %q", unreleased.Content)
+		}
+		released, _ := p.Entry("1.1.0")
+		assertDate(t, released.Date, 2024, time.May, 10)
+		if len(released.Items) != 1 || released.Items[0].Text != "Pending thing" {
+			t.Errorf("items = %+v", released.Items)
+		}
+	})
+
+	t.Run("without links", func(t *testing.T) {
+		e := NewEditor("## [Unreleased]\n- Thing\n")
+		if err := e.Release("0.1.0", date); err != nil {
+			t.Fatal(err)
+		}
+		want := "## [Unreleased]\n\n## [0.1.0] - 2024-05-10\n- Thing\n"
+		if e.String() != want {
+			t.Errorf("got %q, want %q", e.String(), want)
+		}
+	})
+
+	t.Run("unprefixed tags", func(t *testing.T) {
+		content := "## [Unreleased]\n\n## [1.0.0]\n\n[unreleased]: https://gitlab.com/o/r/-/compare/1.0.0...HEAD\n"
+		e := NewEditor(content)
+		if err := e.Release("1.0.1", date); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(e.String(), "[unreleased]: https://gitlab.com/o/r/-/compare/1.0.1...HEAD\n[1.0.1]: https://gitlab.com/o/r/-/compare/1.0.0...1.0.1\n") {
+			t.Errorf("links not updated:\n%s", e.String())
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		if err := NewEditor("## [1.0.0]\n").Release("1.0.1", date); err == nil {
+			t.Error("expected error without Unreleased section")
+		}
+		if err := NewEditor(editorFixture).Release("1.0.0", date); err == nil {
+			t.Error("expected error releasing an existing version")
+		}
+	})
+}
+
+func TestEditorRST(t *testing.T) {
+	content := "Changelog\n=========\n\nUnreleased\n----------\n\n* Pending\n\n1.0.0\n-----\n\nFixed\n~~~~~\n\n* Bug\n"
+	e := NewEditor(content)
+	date := time.Date(2024, time.April, 2, 0, 0, 0, 0, time.UTC)
+
+	if err := e.SetDate("1.0.0", date); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.AddItem("Unreleased", "Added", "Feature"); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Release("1.1.0", date); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "Changelog\n=========\n\nUnreleased\n----------\n\n" +
+		"1.1.0 (2024-04-02)\n------------------\n\n* Pending\n\nAdded\n~~~~~\n\n- Feature\n\n" +
+		"1.0.0 (2024-04-02)\n------------------\n\nFixed\n~~~~~\n\n* Bug\n"
+	if e.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", e.String(), want)
+	}
+}
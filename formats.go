@@ -0,0 +1,288 @@
+package changelog
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sync"
+)
+
+// FormatHandler implements a changelog format. The built-in formats
+// implement it, and applications can add their own with RegisterFormat.
+type FormatHandler interface {
+	// Name identifies the format, for example "keep-a-changelog".
+	Name() string
+
+	// Detect reports how confident the format is that content is written
+	// in it, from 0 (not at all) to 1 (certain), typically from markers
+	// such as its header syntax. Auto-detection weighs it with the headers
+	// the format finds; see DetectFormat.
+	Detect(content string) float64
+
+	// Headers locates the version headers of content, in order.
+	Headers(content string) []Header
+
+	// ParseBody splits the body of an entry, whose first line is line
+	// firstLine (0-based) of the content, into sections and items. Handlers
+	// for Markdown-like bodies can delegate to
+	// FormatMarkdown.Handler().ParseBody.
+	ParseBody(body string, firstLine int) ([]Section, []Item)
+}
+
+// Header locates a version header in a changelog. Offsets are byte
+// offsets into the content, with ends exclusive. An entry's content
+// follows its header.
+type Header struct {
+	// Start and End bound the whole header.
+	Start, End int
+
+	// VersionStart and VersionEnd bound the version string.
+	VersionStart, VersionEnd int
+
+	// DateStart and DateEnd bound the date, if the header has one
+	// (DateEnd > DateStart). It is parsed with the parser's date layouts.
+	DateStart, DateEnd int
+
+	// ContentEnd is where the entry's content stops, such as before a
+	// trailer line. Zero runs it to the next header, or for the last
+	// header to the end of the content.
+	ContentEnd int
+
+	// Metadata copied to the entry.
+	Package    string
+	Author     string
+	Fields     map[string]string
+	Unreleased bool
+}
+
+// builtinFormat is a FormatHandler for a format of this package. Formats
+// are matched by a single pattern, whose first two groups are the version
+// and the date, or by a scanner; bodies use Markdown headings unless
+// heading or body says otherwise.
+type builtinFormat struct {
+	name    string
+	pattern *regexp.Regexp
+	scan    func(content string) []header
+	heading headingFunc
+	body    func(body string, firstLine int) ([]Section, []Item)
+
+	// score is the confidence reported when detect matches. More specific
+	// formats are more confident.
+	score  float64
+	detect func(content string) bool
+}
+
+func (f *builtinFormat) Name() string { return f.name }
+
+func (f *builtinFormat) Detect(content string) float64 {
+	if f.detect(content) {
+		return f.score
+	}
+	return 0
+}
+
+func (f *builtinFormat) Headers(content string) []Header {
+	found := f.headers(content)
+	headers := make([]Header, len(found))
+	for i, h := range found {
+		headers[i] = exportHeader(h)
+	}
+	return headers
+}
+
+func (f *builtinFormat) ParseBody(body string, firstLine int) ([]Section, []Item) {
+	if f.body != nil {
+		return f.body(body, firstLine)
+	}
+	heading := f.heading
+	if heading == nil {
+		heading = markdownHeading
+	}
+	return parseBody(body, firstLine, heading)
+}
+
+func (f *builtinFormat) headers(content string) []header {
+	if f.scan != nil {
+		return f.scan(content)
+	}
+	return patternHeaders(f.pattern, content)
+}
+
+func patternHeaders(pattern *regexp.Regexp, content string) []header {
+	var headers []header
+	for _, match := range pattern.FindAllStringSubmatchIndex(content, -1) {
+		headers = append(headers, header{match: match})
+	}
+	return headers
+}
+
+// builtinFormats holds the built-in formats, indexed by Format.
+var builtinFormats = []*builtinFormat{
+	FormatAuto: nil,
+	FormatKeepAChangelog: {
+		name: "keep-a-changelog", pattern: keepAChangelog,
+		score: 1, detect: keepAChangelog.MatchString,
+	},
+	FormatMarkdown: {
+		name: "markdown", pattern: markdownHeader,
+		score: 0.1, detect: markdownHeader.MatchString,
+	},
+	FormatUnderline: {
+		name: "underline", pattern: underlineHeader,
+		score: 0.5, detect: underlineHeader.MatchString,
+	},
+	FormatRST: {
+		name: "rst", scan: rstHeaders, body: parseRSTBody,
+		score: 0.6, detect: looksLikeRST,
+	},
+	FormatRDoc: {
+		name: "rdoc", pattern: rdocHeader, heading: rdocHeading,
+		score: 0.65, detect: rdocHeader.MatchString,
+	},
+	FormatDebian: {
+		name: "debian", scan: debianHeaders, heading: bracketHeading,
+		score: 0.95, detect: looksLikeDebian,
+	},
+	FormatRPM: {
+		name: "rpm", scan: rpmHeaders, heading: noHeading,
+		score: 0.9, detect: rpmSection.MatchString,
+	},
+	FormatGNUNews: {
+		name: "gnu-news", scan: gnuNewsHeaders, heading: gnuNewsSection,
+		score: 0.85, detect: gnuNewsHeader.MatchString,
+	},
+	FormatGNUChangeLog: {
+		name: "gnu-changelog", scan: gnuChangeLogHeaders, heading: noHeading,
+		score: 0.8, detect: gnuChangeLogHeader.MatchString,
+	},
+	FormatCPAN: {
+		name: "cpan", scan: cpanHeaders, heading: bracketHeading,
+		score: 0.75, detect: cpanDetect.MatchString,
+	},
+	FormatCabal: {
+		name: "cabal", pattern: cabalHeader,
+		score: 0.7, detect: cabalDetect.MatchString,
+	},
+	FormatAsciiDoc: {
+		name: "asciidoc", scan: asciidocHeaders, body: parseAsciiDocBody,
+		score: 0.7, detect: looksLikeAsciiDoc,
+	},
+	FormatOrg: {
+		name: "org", scan: orgHeaders, heading: orgHeading,
+		score: 0.7, detect: looksLikeOrg,
+	},
+}
+
+// formats is the registry of formats, indexed by Format. It starts with
+// the built-in formats.
+var formats = struct {
+	sync.RWMutex
+	handlers []FormatHandler
+}{handlers: func() []FormatHandler {
+	handlers := make([]FormatHandler, len(builtinFormats))
+	for i, f := range builtinFormats {
+		if f != nil {
+			handlers[i] = f
+		}
+	}
+	return handlers
+}()}
+
+// RegisterFormat adds a format to the registry and returns its Format,
+// which can be passed to ParseWithFormat and NewEditorWithFormat.
+// Auto-detection consults registered formats alongside the built-in ones
+// (see DetectFormat). Render writes registered formats as Keep a
+// Changelog. RegisterFormat panics if h is nil or its name is already
+// registered.
+func RegisterFormat(h FormatHandler) Format {
+	if h == nil {
+		panic("changelog: RegisterFormat handler is nil")
+	}
+	formats.Lock()
+	defer formats.Unlock()
+	for _, existing := range formats.handlers {
+		if existing != nil && existing.Name() == h.Name() {
+			panic(fmt.Sprintf("changelog: RegisterFormat called twice for format %q", h.Name()))
+		}
+	}
+	formats.handlers = append(formats.handlers, h)
+	return Format(len(formats.handlers) - 1)
+}
+
+// FormatByName returns the registered format with the given name.
+func FormatByName(name string) (Format, bool) {
+	formats.RLock()
+	defer formats.RUnlock()
+	for i, h := range formats.handlers {
+		if h != nil && h.Name() == name {
+			return Format(i), true
+		}
+	}
+	return FormatAuto, false
+}
+
+// Handler returns the implementation of the format, or nil for FormatAuto
+// and formats that are not registered.
+func (f Format) Handler() FormatHandler {
+	formats.RLock()
+	defer formats.RUnlock()
+	if f < 0 || int(f) >= len(formats.handlers) {
+		return nil
+	}
+	return formats.handlers[f]
+}
+
+// String returns the format's name, "auto" for FormatAuto.
+func (f Format) String() string {
+	if f == FormatAuto {
+		return "auto"
+	}
+	if h := f.Handler(); h != nil {
+		return h.Name()
+	}
+	return fmt.Sprintf("Format(%d)", int(f))
+}
+
+// exportHeader converts an internal header to a Header.
+func exportHeader(h header) Header {
+	m := h.match
+	out := Header{
+		Start: m[0], End: m[1], VersionStart: m[2], VersionEnd: m[3],
+		ContentEnd: h.end, Package: h.pkg, Author: h.author, Fields: h.fields, Unreleased: h.unreleased,
+	}
+	if len(m) > 5 && m[4] >= 0 {
+		out.DateStart, out.DateEnd = m[4], m[5]
+	}
+	return out
+}
+
+// importHeaders converts the Headers of a registered format to internal
+// headers, sorted by position. Headers whose offsets fall outside content
+// or overlap the previous header are dropped.
+func importHeaders(found []Header, content string) []header {
+	found = slices.Clone(found)
+	slices.SortStableFunc(found, func(a, b Header) int { return a.Start - b.Start })
+
+	var headers []header
+	prevEnd := 0
+	in := func(start, end int) bool { return 0 <= start && start <= end && end <= len(content) }
+	for _, h := range found {
+		if h.Start < prevEnd || !in(h.Start, h.End) || !in(h.VersionStart, h.VersionEnd) {
+			continue
+		}
+		match := []int{h.Start, h.End, h.VersionStart, h.VersionEnd, -1, -1}
+		if h.DateEnd > h.DateStart && in(h.DateStart, h.DateEnd) {
+			match[4], match[5] = h.DateStart, h.DateEnd
+		}
+		end := h.ContentEnd
+		if end != 0 && !in(h.End, end) {
+			end = 0
+		}
+		headers = append(headers, header{
+			match: match, end: end,
+			pkg: h.Package, author: h.Author, fields: h.Fields, unreleased: h.Unreleased,
+		})
+		prevEnd = max(h.End, end)
+	}
+	return headers
+}
@@ -0,0 +1,149 @@
+package changelog
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// releaseBlocks is a format whose headers span several lines:
+//
+//	Release: 2.0.0
+//	Date: 2024-03-15
+//	----
+var releaseBlocks = RegisterFormat(releaseBlockFormat{})
+
+var releaseBlock = regexp.MustCompile(`(?m)^Release:[ \t]*(\S+)\n(?:Date:[ \t]*(\S+)\n)?-{4,}$`)
+
+type releaseBlockFormat struct{}
+
+func (releaseBlockFormat) Name() string { return "release-block" }
+
+func (releaseBlockFormat) Detect(content string) float64 {
+	if releaseBlock.MatchString(content) {
+		return 0.9
+	}
+	return 0
+}
+
+func (releaseBlockFormat) Headers(content string) []Header {
+	var headers []Header
+	for _, m := range releaseBlock.FindAllStringSubmatchIndex(content, -1) {
+		headers = append(headers, Header{
+			Start: m[0], End: m[1], VersionStart: m[2], VersionEnd: m[3], DateStart: m[4], DateEnd: m[5],
+			Unreleased: content[m[2]:m[3]] == "next",
+		})
+	}
+	return headers
+}
+
+func (releaseBlockFormat) ParseBody(body string, firstLine int) ([]Section, []Item) {
+	return FormatMarkdown.Handler().ParseBody(body, firstLine)
+}
+
+const releaseBlockContent = `Release: next
+----
+* Pending.
+
+Release: 2.0.0
+Date: 2024-03-15
+----
+### Fixed
+* A bug.
+
+Release: 1.0.0
+----
+* First.
+`
+
+func TestRegisteredFormat(t *testing.T) {
+	if got, ok := FormatByName("release-block"); !ok || got != releaseBlocks {
+		t.Fatalf("FormatByName() = %v, %v", got, ok)
+	}
+	if releaseBlocks.String() != "release-block" {
+		t.Errorf("String() = %q", releaseBlocks.String())
+	}
+
+	p := Parse(releaseBlockContent)
+	if p.format != releaseBlocks {
+		t.Fatalf("detected format %v, want release-block", p.format)
+	}
+	if got := strings.Join(p.Versions(), ","); got != "next,2.0.0,1.0.0" {
+		t.Fatalf("Versions() = %s", got)
+	}
+
+	entry, _ := p.Entry("2.0.0")
+	assertDate(t, entry.Date, 2024, time.March, 15)
+	if len(entry.Sections) != 1 || entry.Sections[0].Category != CategoryFixed || entry.Sections[0].Items[0].Text != "A bug." {
+		t.Errorf("Sections = %+v", entry.Sections)
+	}
+	if entry.Sections[0].StartLine != 7 {
+		t.Errorf("section StartLine = %d, want 7", entry.Sections[0].StartLine)
+	}
+	if _, ok := p.Unreleased(); !ok {
+		t.Error("expected an unreleased entry")
+	}
+	if got := p.LineForVersion("1.0.0"); got != 10 {
+		t.Errorf("LineForVersion(1.0.0) = %d, want 10", got)
+	}
+
+	// Explicitly selected, even where another format would be detected.
+	p = ParseWithFormat("## [1.0.0]\n\nRelease: 0.9.0\n----\n", releaseBlocks)
+	if got := strings.Join(p.Versions(), ","); got != "0.9.0" {
+		t.Errorf("Versions() = %s", got)
+	}
+}
+
+func TestRegisterFormatDuplicate(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic")
+		}
+	}()
+	RegisterFormat(releaseBlockFormat{})
+}
+
+func TestBuiltinFormats(t *testing.T) {
+	for _, name := range []string{"keep-a-changelog", "markdown", "underline", "rst", "rdoc", "debian", "rpm", "gnu-news", "gnu-changelog", "cpan", "cabal", "asciidoc", "org"} {
+		f, ok := FormatByName(name)
+		if !ok || f.String() != name {
+			t.Errorf("FormatByName(%q) = %v, %v", name, f, ok)
+		}
+	}
+	if FormatAuto.String() != "auto" || FormatAuto.Handler() != nil {
+		t.Errorf("FormatAuto = %q, %v", FormatAuto.String(), FormatAuto.Handler())
+	}
+	if _, ok := FormatByName("nope"); ok {
+		t.Error("FormatByName(nope) should fail")
+	}
+
+	content := mustReadFixture(t, "debian_changelog")
+	headers := FormatDebian.Handler().Headers(content)
+	if len(headers) == 0 {
+		t.Fatal("no headers")
+	}
+	h := headers[0]
+	if content[h.VersionStart:h.VersionEnd] != "1:2.3-5" || h.DateEnd <= h.DateStart || h.ContentEnd == 0 || h.Package == "" {
+		t.Errorf("Headers()[0] = %+v", h)
+	}
+	if FormatDebian.Handler().Detect(content) <= FormatMarkdown.Handler().Detect(content) {
+		t.Error("Debian should outscore Markdown")
+	}
+}
+
+func TestImportHeaders(t *testing.T) {
+	content := "a 1.0\nb 2.0\n"
+	headers := importHeaders([]Header{
+		{Start: 6, End: 11, VersionStart: 8, VersionEnd: 11},
+		{Start: 0, End: 5, VersionStart: 2, VersionEnd: 5, DateStart: 3, DateEnd: 99},
+		{Start: 2, End: 4, VersionStart: 2, VersionEnd: 4}, // overlaps the first
+		{Start: 20, End: 30, VersionStart: 20, VersionEnd: 30},
+	}, content)
+	if len(headers) != 2 || headers[0].match[0] != 0 || headers[1].match[0] != 6 {
+		t.Fatalf("headers = %+v", headers)
+	}
+	if headers[0].match[4] != -1 {
+		t.Errorf("out-of-range date kept: %v", headers[0].match)
+	}
+}
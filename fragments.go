@@ -0,0 +1,487 @@
+package changelog
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Fragment loaders read the pending changes that release tools keep as one
+// file per change, rather than in a changelog, and return them as
+// Unreleased entries. Each section holds the changes of one kind, in the
+// order the tool would release them, with one item per change. Entry
+// Content is the body rendered as Markdown. Fragments have no position in
+// a changelog, so line numbers are not set.
+
+type fragmentDir struct {
+	dir  string
+	load func(dir string) ([]Entry, error)
+}
+
+// fragmentDirs are the directories, relative to a checkout, that
+// FindAndLoadFragments looks in, in order.
+var fragmentDirs = []fragmentDir{
+	{filepath.Join(".changes", "unreleased"), LoadChangie},
+	{".changeset", LoadChangesets},
+	{"newsfragments", loadTowncrier},
+	{"changelog.d", loadTowncrier},
+}
+
+// FindAndLoadFragments looks in directory, the root of a checkout, for
+// the fragment directories of changie (.changes/unreleased), changesets
+// (.changeset) and towncrier (newsfragments, changelog.d, or the directory
+// set in towncrier.toml or pyproject.toml), and loads the fragments of
+// each one found. It returns nil when there are none.
+func FindAndLoadFragments(directory string) ([]Entry, error) {
+	dirs := slices.Clone(fragmentDirs)
+	if dir := towncrierDirectory(directory); dir != "" {
+		dirs = append(dirs, fragmentDir{dir, loadTowncrier})
+	}
+
+	var entries []Entry
+	seen := map[string]bool{}
+	for _, d := range dirs {
+		path := filepath.Join(directory, d.dir)
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+		if info, err := os.Stat(path); err != nil || !info.IsDir() {
+			continue
+		}
+		loaded, err := d.load(path)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, loaded...)
+	}
+	return entries, nil
+}
+
+// fragment is one pending change.
+type fragment struct {
+	kind  string // section the change belongs to
+	group string // package or component, for monorepos
+	text  string
+	order string // sort key within the section
+}
+
+// fragmentEntries groups fragments into one Unreleased entry per group,
+// ordered by name, with sections ordered by rank and then by name.
+func fragmentEntries(fragments []fragment, rank func(kind string) int) []Entry {
+	groups := map[string][]fragment{}
+	for _, f := range fragments {
+		groups[f.group] = append(groups[f.group], f)
+	}
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	entries := make([]Entry, 0, len(names))
+	for _, name := range names {
+		group := groups[name]
+		slices.SortStableFunc(group, func(a, b fragment) int {
+			return cmp.Or(
+				cmp.Compare(rank(a.kind), rank(b.kind)),
+				strings.Compare(a.kind, b.kind),
+				compareNatural(a.order, b.order),
+			)
+		})
+
+		e := Entry{Version: "Unreleased", Unreleased: true, Package: name}
+		for _, f := range group {
+			if f.kind == "" {
+				e.Items = append(e.Items, Item{Text: f.text})
+				continue
+			}
+			if n := len(e.Sections); n == 0 || e.Sections[n-1].Name != f.kind {
+				e.Sections = append(e.Sections, Section{Name: f.kind, Category: ParseCategory(f.kind)})
+			}
+			s := &e.Sections[len(e.Sections)-1]
+			s.Items = append(s.Items, Item{Text: f.text})
+		}
+		for i, s := range e.Sections {
+			e.Sections[i].Content = renderItems(FormatMarkdown, s.Items)
+			e.Items = append(e.Items, s.Items...)
+		}
+		e.Content = renderBody(FormatMarkdown, e)
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+// compareNatural orders strings with runs of digits compared by value, so
+// that "9" sorts before "10".
+func compareNatural(a, b string) int {
+	for a != "" && b != "" {
+		ad, bd := leadingDigits(a), leadingDigits(b)
+		if ad != "" && bd != "" {
+			an, _ := strconv.ParseUint(ad, 10, 64)
+			bn, _ := strconv.ParseUint(bd, 10, 64)
+			if c := cmp.Compare(an, bn); c != 0 {
+				return c
+			}
+			a, b = a[len(ad):], b[len(bd):]
+			continue
+		}
+		if c := cmp.Compare(a[0], b[0]); c != 0 {
+			return c
+		}
+		a, b = a[1:], b[1:]
+	}
+	return cmp.Compare(len(a), len(b))
+}
+
+func leadingDigits(s string) string {
+	i := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	return s[:i]
+}
+
+// foldText joins the lines of a fragment's text into one line, as item
+// continuation lines are in a parsed changelog.
+func foldText(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// fragmentFiles lists the regular files in dir whose names have one of
+// the given extensions, in name order. Hidden files are skipped.
+func fragmentFiles(dir string, exts ...string) ([]string, error) {
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, e := range dirEntries {
+		name := e.Name()
+		if e.IsDir() || strings.HasPrefix(name, ".") {
+			continue
+		}
+		if len(exts) > 0 && !slices.Contains(exts, strings.ToLower(filepath.Ext(name))) {
+			continue
+		}
+		files = append(files, name)
+	}
+	return files, nil
+}
+
+// LoadChangie reads the unreleased fragments of changie, the YAML files in
+// dir (usually .changes/unreleased). Each fragment's kind is a section,
+// ordered like Keep a Changelog's categories with other kinds after them,
+// and its body is an item; changes are ordered by their time. Fragments
+// for a component of a monorepo are returned as a separate entry with the
+// component as Package.
+func LoadChangie(dir string) ([]Entry, error) {
+	files, err := fragmentFiles(dir, ".yaml", ".yml")
+	if err != nil {
+		return nil, err
+	}
+	var fragments []fragment
+	for _, name := range files {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		values := parseSimpleYAML(string(data))
+		if values["body"] == "" {
+			continue
+		}
+		f := fragment{
+			kind:  values["kind"],
+			group: values["component"],
+			text:  foldText(values["body"]),
+			order: name,
+		}
+		if t, err := time.Parse(time.RFC3339Nano, values["time"]); err == nil {
+			f.order = t.UTC().Format("2006-01-02T15:04:05.000000000") + name
+		}
+		fragments = append(fragments, f)
+	}
+	return fragmentEntries(fragments, func(kind string) int {
+		if c := ParseCategory(kind); c != CategoryUnknown {
+			return int(c)
+		}
+		return len(categoryNames)
+	}), nil
+}
+
+var changesetBumps = map[string]string{
+	"major": "Major Changes",
+	"minor": "Minor Changes",
+	"patch": "Patch Changes",
+}
+
+// LoadChangesets reads the pending changesets in dir (usually
+// .changeset). Each changeset names the packages it bumps in its front
+// matter, and its summary becomes an item in every one of them. There is
+// an entry per package, with the package as Package and "Major Changes",
+// "Minor Changes" and "Patch Changes" sections as in the changelogs that
+// changesets writes. Changesets that bump no packages are skipped.
+func LoadChangesets(dir string) ([]Entry, error) {
+	files, err := fragmentFiles(dir, ".md")
+	if err != nil {
+		return nil, err
+	}
+	var fragments []fragment
+	for _, name := range files {
+		if strings.EqualFold(name, "README.md") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		front, summary, ok := frontMatter(string(data))
+		if !ok {
+			return nil, fmt.Errorf("changeset %s has no front matter", name)
+		}
+		for pkg, bump := range parseSimpleYAML(front) {
+			kind, ok := changesetBumps[strings.ToLower(bump)]
+			if !ok {
+				continue
+			}
+			fragments = append(fragments, fragment{kind: kind, group: pkg, text: foldText(summary), order: name})
+		}
+	}
+	return fragmentEntries(fragments, func(kind string) int {
+		return slices.Index([]string{"Major Changes", "Minor Changes", "Patch Changes"}, kind)
+	}), nil
+}
+
+var frontMatterEnd = regexp.MustCompile(`(?m)^---[ \t]*$`)
+
+// frontMatter splits a Markdown file into its "---" delimited front
+// matter and the rest of the file.
+func frontMatter(s string) (string, string, bool) {
+	s = strings.TrimPrefix(s, "\ufeff")
+	if !strings.HasPrefix(s, "---") {
+		return "", "", false
+	}
+	rest := s[nextLine(s, 0):]
+	loc := frontMatterEnd.FindStringIndex(rest)
+	if loc == nil {
+		return "", "", false
+	}
+	return rest[:loc[0]], strings.TrimSpace(rest[loc[1]:]), true
+}
+
+// TowncrierType is a kind of towncrier news fragment: the type in a
+// fragment's file name ("123.feature.md") and the title of its section.
+type TowncrierType struct {
+	Name  string
+	Title string
+}
+
+// DefaultTowncrierTypes are towncrier's default fragment types, in the
+// order their sections are written.
+var DefaultTowncrierTypes = []TowncrierType{
+	{"feature", "Features"},
+	{"bugfix", "Bugfixes"},
+	{"doc", "Improved Documentation"},
+	{"removal", "Deprecations and Removals"},
+	{"misc", "Misc"},
+}
+
+var towncrierFragment = regexp.MustCompile(`^(.+?)\.([A-Za-z_-]+)(?:\.\d+)?(?:\.(?:md|rst|txt))?$`)
+
+// LoadTowncrier reads the news fragments of towncrier in dir, files named
+// "<issue>.<type>" with an optional counter and extension, such as
+// "123.feature.md" or "+orphan.bugfix". Types default to
+// DefaultTowncrierTypes; files of other types are ignored. Each type is a
+// section and each fragment an item, ending with its issue reference
+// ("Text (#123)") unless it is an orphan (the issue starts with "+").
+// Fragments with the same text are merged into one item listing all their
+// issues, and Misc items without text list only the issues.
+func LoadTowncrier(dir string, types ...TowncrierType) ([]Entry, error) {
+	if len(types) == 0 {
+		types = DefaultTowncrierTypes
+	}
+	files, err := fragmentFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	type change struct {
+		kind, text string
+		issues     []string
+		order      string
+	}
+	var changes []*change
+	byText := map[[2]string]*change{}
+	for _, name := range files {
+		m := towncrierFragment.FindStringSubmatch(name)
+		if m == nil {
+			continue
+		}
+		i := slices.IndexFunc(types, func(t TowncrierType) bool { return t.Name == m[2] })
+		if i < 0 {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		kind, text := types[i].Title, foldText(string(data))
+		c, ok := byText[[2]string{kind, text}]
+		if !ok || (text == "" && types[i].Name != "misc") {
+			c = &change{kind: kind, text: text, order: m[1]}
+			byText[[2]string{kind, text}] = c
+			changes = append(changes, c)
+		}
+		if issue := m[1]; !strings.HasPrefix(issue, "+") {
+			if _, err := strconv.Atoi(issue); err == nil {
+				issue = "#" + issue
+			}
+			c.issues = append(c.issues, issue)
+		}
+	}
+
+	fragments := make([]fragment, 0, len(changes))
+	for _, c := range changes {
+		slices.SortFunc(c.issues, compareNatural)
+		text := c.text
+		if refs := strings.Join(c.issues, ", "); refs != "" {
+			if text == "" {
+				text = refs
+			} else {
+				text += " (" + refs + ")"
+			}
+		}
+		if text == "" {
+			continue
+		}
+		fragments = append(fragments, fragment{kind: c.kind, text: text, order: c.order})
+	}
+	return fragmentEntries(fragments, func(kind string) int {
+		return slices.IndexFunc(types, func(t TowncrierType) bool { return t.Title == kind })
+	}), nil
+}
+
+func loadTowncrier(dir string) ([]Entry, error) {
+	return LoadTowncrier(dir)
+}
+
+var (
+	towncrierSection      = regexp.MustCompile(`(?m)^\[tool\.towncrier\][ \t]*$`)
+	towncrierDirectoryKey = regexp.MustCompile(`(?m)^directory[ \t]*=[ \t]*["']([^"'\n]+)["']`)
+)
+
+// towncrierDirectory returns the fragment directory set in the towncrier
+// configuration of the checkout at directory, if any.
+func towncrierDirectory(directory string) string {
+	for _, name := range []string{"towncrier.toml", "pyproject.toml"} {
+		data, err := os.ReadFile(filepath.Join(directory, name))
+		if err != nil {
+			continue
+		}
+		config := string(data)
+		if loc := towncrierSection.FindStringIndex(config); loc != nil {
+			config = config[loc[1]:]
+			if next := strings.Index(config, "\n["); next >= 0 {
+				config = config[:next]
+			}
+		} else if name != "towncrier.toml" {
+			continue
+		}
+		if m := towncrierDirectoryKey.FindStringSubmatch(config); m != nil {
+			return filepath.FromSlash(m[1])
+		}
+	}
+	return ""
+}
+
+// parseSimpleYAML reads the top-level scalar values of a YAML mapping,
+// which is all fragment files need: plain and quoted scalars, and "|" and
+// ">" block scalars. Nested values are skipped.
+func parseSimpleYAML(s string) map[string]string {
+	values := map[string]string{}
+	lines := strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if line == "" || line[0] == ' ' || line[0] == '\t' || line[0] == '#' || strings.HasPrefix(line, "---") {
+			continue
+		}
+		key, value, ok := splitYAMLKey(line)
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if value == "" || (value[0] != '|' && value[0] != '>') {
+			values[key] = yamlScalar(value)
+			continue
+		}
+
+		var block []string
+		for i+1 < len(lines) && (lines[i+1] == "" || lines[i+1][0] == ' ' || lines[i+1][0] == '\t') {
+			i++
+			block = append(block, lines[i])
+		}
+		text := dedent(block)
+		if value[0] == '>' {
+			text = foldText(text)
+		}
+		values[key] = text
+	}
+	return values
+}
+
+// splitYAMLKey splits a "key: value" line, unquoting the key.
+func splitYAMLKey(line string) (string, string, bool) {
+	if q := line[0]; q == '"' || q == '\'' {
+		end := strings.IndexByte(line[1:], q)
+		if end < 0 || !strings.HasPrefix(line[end+2:], ":") {
+			return "", "", false
+		}
+		return line[1 : end+1], line[end+3:], true
+	}
+	key, value, ok := strings.Cut(line, ":")
+	if !ok || (value != "" && value[0] != ' ' && value[0] != '\t') {
+		return "", "", false
+	}
+	return strings.TrimSpace(key), value, true
+}
+
+// yamlScalar unquotes a single-line YAML scalar and drops a trailing
+// comment from a plain one.
+func yamlScalar(s string) string {
+	switch {
+	case strings.HasPrefix(s, `"`):
+		if u, err := strconv.Unquote(s); err == nil {
+			return u
+		}
+		return strings.Trim(s, `"`)
+	case strings.HasPrefix(s, "'"):
+		return strings.ReplaceAll(strings.Trim(s, "'"), "''", "'")
+	}
+	if i := strings.Index(s, " #"); i >= 0 {
+		s = strings.TrimSpace(s[:i])
+	}
+	return s
+}
+
+// dedent removes the indentation of the first non-blank line from each
+// line of a block and trims trailing blank lines.
+func dedent(lines []string) string {
+	indent := ""
+	for _, line := range lines {
+		if trimmed := strings.TrimLeft(line, " \t"); trimmed != "" {
+			indent = line[:len(line)-len(trimmed)]
+			break
+		}
+	}
+	out := make([]string, len(lines))
+	for i, line := range lines {
+		out[i] = strings.TrimPrefix(line, indent)
+	}
+	return strings.TrimRight(strings.Join(out, "\n"), "\n")
+}
@@ -0,0 +1,198 @@
+package changelog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func itemTexts(items []Item) string {
+	texts := make([]string, len(items))
+	for i, it := range items {
+		texts[i] = it.Text
+	}
+	return strings.Join(texts, "|")
+}
+
+func TestLoadChangie(t *testing.T) {
+	entries, err := LoadChangie(filepath.Join("testdata", "fragments", ".changes", "unreleased"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries", len(entries))
+	}
+	e := entries[0]
+	if e.Version != "Unreleased" || !e.Unreleased || e.Package != "" {
+		t.Errorf("entry = %+v", e)
+	}
+	if len(e.Sections) != 2 || e.Sections[0].Category != CategoryAdded || e.Sections[1].Category != CategoryFixed {
+		t.Fatalf("Sections = %+v", e.Sections)
+	}
+	if got := itemTexts(e.Sections[0].Items); got != "Add the --quiet flag, which suppresses progress output.|Add JSON output: use --format=json" {
+		t.Errorf("Added items = %q", got)
+	}
+	if len(e.Items) != 3 {
+		t.Errorf("Items = %+v", e.Items)
+	}
+	if !strings.HasPrefix(e.Content, "### Added\n\n- Add the --quiet flag") {
+		t.Errorf("Content = %q", e.Content)
+	}
+}
+
+func TestLoadChangieComponents(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.yaml": "component: api\nkind: Fixed\nbody: Fix the API.\n",
+		"b.yaml": "component: cli\nkind: Added\nbody: Add a flag.\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries, err := LoadChangie(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Package != "api" || entries[1].Package != "cli" {
+		t.Errorf("entries = %+v", entries)
+	}
+}
+
+func TestLoadChangesets(t *testing.T) {
+	entries, err := LoadChangesets(filepath.Join("testdata", "fragments", ".changeset"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Package != "@acme/cli" || entries[1].Package != "@acme/core" {
+		t.Fatalf("entries = %+v", entries)
+	}
+
+	cli := entries[0]
+	if len(cli.Sections) != 1 || cli.Sections[0].Name != "Patch Changes" || itemTexts(cli.Items) != "Add a retry option to the client." {
+		t.Errorf("@acme/cli = %+v", cli)
+	}
+	core := entries[1]
+	if len(core.Sections) != 2 || core.Sections[0].Name != "Minor Changes" || core.Sections[1].Name != "Patch Changes" {
+		t.Errorf("@acme/core sections = %+v", core.Sections)
+	}
+	if got := itemTexts(core.Sections[1].Items); got != "Fix the retry delay." {
+		t.Errorf("@acme/core patch items = %q", got)
+	}
+}
+
+func TestLoadChangesetsMissingFrontMatter(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "bad.md"), []byte("Just text.\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadChangesets(dir); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestLoadTowncrier(t *testing.T) {
+	entries, err := LoadTowncrier(filepath.Join("testdata", "fragments", "newsfragments"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries", len(entries))
+	}
+	e := entries[0]
+	var names []string
+	for _, s := range e.Sections {
+		names = append(names, s.Name+": "+itemTexts(s.Items))
+	}
+	want := []string{
+		"Features: Add a ``--verbose`` option. (#12)",
+		"Bugfixes: Fix a crash on startup. (#9, #10)",
+		"Improved Documentation: Document the config file.",
+		"Misc: #15",
+	}
+	if got := strings.Join(names, "\n"); got != strings.Join(want, "\n") {
+		t.Errorf("sections:\n%s\nwant:\n%s", got, strings.Join(want, "\n"))
+	}
+	if e.Sections[0].Category != CategoryAdded || e.Sections[1].Category != CategoryFixed {
+		t.Errorf("categories = %v, %v", e.Sections[0].Category, e.Sections[1].Category)
+	}
+}
+
+func TestLoadTowncrierCustomTypes(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"1.breaking.md": "Drop Python 3.8.\n",
+		"2.feature.md":  "Ignored.\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries, err := LoadTowncrier(dir, TowncrierType{Name: "breaking", Title: "Breaking Changes"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || len(entries[0].Sections) != 1 || itemTexts(entries[0].Items) != "Drop Python 3.8. (#1)" {
+		t.Errorf("entries = %+v", entries)
+	}
+}
+
+func TestFindAndLoadFragments(t *testing.T) {
+	entries, err := FindAndLoadFragments(filepath.Join("testdata", "fragments"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var packages []string
+	for _, e := range entries {
+		packages = append(packages, e.Package)
+	}
+	// changie, then changesets, then towncrier.
+	if got := strings.Join(packages, ","); got != ",@acme/cli,@acme/core," {
+		t.Errorf("packages = %q", got)
+	}
+
+	t.Run("towncrier directory from config", func(t *testing.T) {
+		dir := t.TempDir()
+		config := "[project]\nname = \"x\"\n\n[tool.towncrier]\ndirectory = \"docs/changes\"\n"
+		if err := os.WriteFile(filepath.Join(dir, "pyproject.toml"), []byte(config), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(filepath.Join(dir, "docs", "changes"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "docs", "changes", "3.bugfix"), []byte("Fix.\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		entries, err := FindAndLoadFragments(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != 1 || itemTexts(entries[0].Items) != "Fix. (#3)" {
+			t.Errorf("entries = %+v", entries)
+		}
+	})
+
+	t.Run("none", func(t *testing.T) {
+		entries, err := FindAndLoadFragments(t.TempDir())
+		if err != nil || entries != nil {
+			t.Errorf("FindAndLoadFragments() = %v, %v", entries, err)
+		}
+	})
+}
+
+func TestParseSimpleYAML(t *testing.T) {
+	got := parseSimpleYAML("a: plain # comment\n\"b\": \"quoted \\\"x\\\"\"\nc: 'it''s'\nd: >\n  folded\n  text\nnested:\n  e: skipped\nf: |\n  line one\n\n  line two\n")
+	want := map[string]string{
+		"a": "plain", "b": `quoted "x"`, "c": "it's", "d": "folded text", "nested": "", "f": "line one\n\nline two",
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %q, want %q", k, got[k], v)
+		}
+	}
+	if _, ok := got["e"]; ok {
+		t.Error("nested key should be skipped")
+	}
+}
@@ -0,0 +1,101 @@
+package changelog
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	gnuNewsHeader = regexp.MustCompile(`(?m)^\*[ \t]+(?i:(?:noteworthy[ \t]+)?changes[ \t]+in[ \t]+(?:release|version))[ \t]+` +
+		`v?([^\s()\[\]]+?):?(?:[ \t]+\(([^)\n]*)\))?(?:[ \t]+\[([^\]\n]*)\])?[ \t]*$`)
+	gnuNewsHeading     = regexp.MustCompile(`^\*\*+[ \t]+(.+?)[ \t]*$`)
+	gnuChangeLogHeader = regexp.MustCompile(`(?m)^(\d{4}-\d{2}-\d{2}|` +
+		`(?:Mon|Tue|Wed|Thu|Fri|Sat|Sun) [A-Z][a-z]{2} [ \d]\d \d{2}:\d{2}:\d{2}(?: [A-Z]+)? \d{4})` +
+		`[ \t]+([^\n<]*?<[^>\n]+>)[ \t]*$`)
+)
+
+// gnuNewsHeaders finds the release headings of a GNU NEWS file:
+//
+//	GNU Hello NEWS
+//
+//	* Noteworthy changes in release 2.4 (2024-03-15) [stable]
+//
+// The bracketed stability tag is kept as the "stability" field. The
+// gnulib placeholder heading for the next release ("release ?.?
+// (????-??-??) [?]") is marked Unreleased and has no date.
+func gnuNewsHeaders(content string) []header {
+	var headers []header
+	for _, m := range gnuNewsHeader.FindAllStringSubmatchIndex(content, -1) {
+		h := header{match: m[:6]}
+		version := content[m[2]:m[3]]
+		if strings.Contains(version, "?") {
+			h.unreleased = true
+		}
+		if m[4] >= 0 && strings.Contains(content[m[4]:m[5]], "?") {
+			h.match[4], h.match[5] = -1, -1
+		}
+		if m[6] >= 0 {
+			if tag := strings.TrimSpace(content[m[6]:m[7]]); tag != "" && tag != "?" {
+				h.fields = map[string]string{"stability": tag}
+			}
+		}
+		headers = append(headers, h)
+	}
+	return headers
+}
+
+// gnuNewsSection treats "** Name" lines as section headings.
+func gnuNewsSection(lines []string, i int) (string, int, bool) {
+	if m := gnuNewsHeading.FindStringSubmatch(lines[i]); m != nil {
+		return m[1], 1, true
+	}
+	return "", 0, false
+}
+
+// gnuChangeLogHeaders finds the date and author lines that start each
+// stanza of a GNU ChangeLog:
+//
+//	2024-03-15  Jane Doe  <jane@example.com>
+//
+// ChangeLog stanzas are not versioned, so the date as written serves as
+// the entry's version. The author, with their email, is kept on the entry.
+func gnuChangeLogHeaders(content string) []header {
+	var headers []header
+	for _, m := range gnuChangeLogHeader.FindAllStringSubmatchIndex(content, -1) {
+		headers = append(headers, header{
+			match:  []int{m[0], m[1], m[2], m[3], m[2], m[3]},
+			author: strings.Join(strings.Fields(content[m[4]:m[5]]), " "),
+		})
+	}
+	return headers
+}
+
+// renderGNUNewsHeader writes a GNU NEWS release heading.
+func renderGNUNewsHeader(e Entry) string {
+	header := "* Noteworthy changes in release " + e.Version
+	if e.Date != nil {
+		header += " (" + e.Date.Format("2006-01-02") + ")"
+	}
+	if tag := e.Fields["stability"]; tag != "" {
+		header += " [" + tag + "]"
+	}
+	return header
+}
+
+// renderGNUChangeLogHeader writes the date and author line of a GNU
+// ChangeLog stanza. The version is used when the entry has no date. Only
+// stanzas with a date and a "Name <email>" author parse back.
+func renderGNUChangeLogHeader(e Entry) string {
+	date := e.Version
+	if e.Date != nil {
+		date = e.Date.Format("2006-01-02")
+	}
+	if e.Author == "" {
+		return date
+	}
+	name, email, ok := strings.Cut(e.Author, "<")
+	if !ok {
+		return date + "  " + e.Author
+	}
+	return date + "  " + strings.TrimSpace(name) + "  <" + email
+}
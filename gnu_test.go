@@ -0,0 +1,108 @@
+package changelog
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGNUNewsFormat(t *testing.T) {
+	p := Parse(mustReadFixture(t, "gnu_news"))
+	if p.format != FormatGNUNews {
+		t.Fatalf("detected format %d, want FormatGNUNews", p.format)
+	}
+	if got := strings.Join(p.Versions(), ","); got != "?.?,2.12.1,2.12" {
+		t.Fatalf("Versions() = %s", got)
+	}
+
+	t.Run("version, date and stability", func(t *testing.T) {
+		entry, _ := p.Entry("2.12.1")
+		assertDate(t, entry.Date, 2023, time.May, 30)
+		if entry.Fields["stability"] != "stable" {
+			t.Errorf("Fields = %v", entry.Fields)
+		}
+		entry, _ = p.Entry("2.12")
+		if entry.Fields["stability"] != "beta" {
+			t.Errorf("Fields = %v", entry.Fields)
+		}
+	})
+
+	t.Run("placeholder release", func(t *testing.T) {
+		entry, ok := p.Unreleased()
+		if !ok || entry.Version != "?.?" || entry.Date != nil || entry.Fields != nil {
+			t.Errorf("Unreleased() = %+v, %v", entry, ok)
+		}
+	})
+
+	t.Run("sections", func(t *testing.T) {
+		entry, _ := p.Entry("2.12.1")
+		if len(entry.Sections) != 2 || entry.Sections[0].Name != "Bug fixes" || len(entry.Sections[0].Items) != 2 {
+			t.Errorf("Sections = %+v", entry.Sections)
+		}
+	})
+
+	t.Run("other headings", func(t *testing.T) {
+		p := Parse("* Changes in version 1.4:\n\n  - Faster.\n\n* Changes in version 1.3:\n\n  - Smaller.\n")
+		if got := strings.Join(p.Versions(), ","); got != "1.4,1.3" {
+			t.Errorf("Versions() = %s", got)
+		}
+	})
+}
+
+func TestGNUChangeLogFormat(t *testing.T) {
+	p := Parse(mustReadFixture(t, "gnu_changelog"))
+	if p.format != FormatGNUChangeLog {
+		t.Fatalf("detected format %d, want FormatGNUChangeLog", p.format)
+	}
+	if got := strings.Join(p.Versions(), ","); got != "2024-03-15,2024-03-10,Mon Jan  8 12:00:00 2024" {
+		t.Fatalf("Versions() = %q", got)
+	}
+
+	entry, _ := p.Entry("2024-03-15")
+	assertDate(t, entry.Date, 2024, time.March, 15)
+	if entry.Author != "Jane Doe <jane@example.com>" {
+		t.Errorf("Author = %q", entry.Author)
+	}
+	if len(entry.Items) != 2 || entry.Items[0].Text != "src/hello.c (main): Handle empty input." {
+		t.Errorf("Items = %+v", entry.Items)
+	}
+
+	entry, _ = p.Entry("2024-03-10")
+	if len(entry.Items) != 1 || entry.Items[0].Text != "configure.ac: Bump version, and regenerate." {
+		t.Errorf("Items = %+v", entry.Items)
+	}
+
+	entry, _ = p.Entry("Mon Jan  8 12:00:00 2024")
+	assertDate(t, entry.Date, 2024, time.January, 8)
+
+	if got := strings.Join(p.SortedVersions(), ","); got != "Mon Jan  8 12:00:00 2024,2024-03-15,2024-03-10" {
+		t.Errorf("SortedVersions() = %q", got)
+	}
+}
+
+func TestRenderGNU(t *testing.T) {
+	date := time.Date(2024, time.March, 15, 0, 0, 0, 0, time.UTC)
+
+	news := Render(FormatGNUNews, []Entry{{
+		Version:  "2.4",
+		Date:     &date,
+		Fields:   map[string]string{"stability": "stable"},
+		Sections: []Section{{Name: "Bug fixes", Items: []Item{{Text: "Fix"}}}},
+	}})
+	if want := "* Noteworthy changes in release 2.4 (2024-03-15) [stable]\n\n** Bug fixes\n\n- Fix\n"; news != want {
+		t.Errorf("Render(FormatGNUNews) = %q, want %q", news, want)
+	}
+
+	log := Render(FormatGNUChangeLog, []Entry{{
+		Version: "2024-03-15",
+		Date:    &date,
+		Author:  "Jane Doe <jane@example.com>",
+		Items:   []Item{{Text: "src/hello.c: Fix."}},
+	}})
+	if want := "2024-03-15  Jane Doe  <jane@example.com>\n\n\t* src/hello.c: Fix.\n"; log != want {
+		t.Errorf("Render(FormatGNUChangeLog) = %q, want %q", log, want)
+	}
+	if entry, ok := Parse(log).Entry("2024-03-15"); !ok || entry.Author != "Jane Doe <jane@example.com>" {
+		t.Errorf("round trip = %+v", entry)
+	}
+}
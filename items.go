@@ -0,0 +1,117 @@
+package changelog
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Item is a single bullet in a changelog entry. Nested bullets are held in
+// Children. StartLine and EndLine are the 0-based, inclusive line numbers of
+// the item in the original content, matching LineForVersion; an item's
+// range covers its continuation lines and children.
+type Item struct {
+	Text      string
+	Children  []Item
+	StartLine int
+	EndLine   int
+}
+
+var bulletLine = regexp.MustCompile(`^(\s*)[-*+]\s+(.*)$`)
+
+type itemNode struct {
+	item          Item
+	indent        int
+	contentIndent int
+	text          []string
+	children      []*itemNode
+}
+
+func (n *itemNode) build() Item {
+	it := n.item
+	it.Text = strings.Join(n.text, " ")
+	for _, c := range n.children {
+		it.Children = append(it.Children, c.build())
+	}
+	return it
+}
+
+// parseItems builds the bullet tree for a block of lines whose first line
+// is line number firstLine in the original content. Continuation lines are
+// folded into the innermost item they belong to: lazily when they directly
+// follow the item, or by indentation after a blank line.
+func parseItems(lines []string, firstLine int) []Item {
+	var roots []*itemNode
+	var stack []*itemNode
+	sawBlank := false
+
+	extend := func(line int) {
+		for _, n := range stack {
+			n.item.EndLine = line
+		}
+	}
+
+	for i, line := range lines {
+		lineNo := firstLine + i
+		if strings.TrimSpace(line) == "" {
+			sawBlank = true
+			continue
+		}
+
+		if m := bulletLine.FindStringSubmatch(line); m != nil {
+			indent := indentWidth(m[1])
+			for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+				stack = stack[:len(stack)-1]
+			}
+			n := &itemNode{
+				item:          Item{StartLine: lineNo, EndLine: lineNo},
+				indent:        indent,
+				contentIndent: indent + len(line) - len(m[1]) - len(m[2]),
+				text:          []string{strings.TrimSpace(m[2])},
+			}
+			if len(stack) == 0 {
+				roots = append(roots, n)
+			} else {
+				stack[len(stack)-1].children = append(stack[len(stack)-1].children, n)
+			}
+			stack = append(stack, n)
+			extend(lineNo)
+			sawBlank = false
+			continue
+		}
+
+		if len(stack) > 0 && sawBlank {
+			indent := indentWidth(line[:len(line)-len(strings.TrimLeft(line, " \t"))])
+			for len(stack) > 0 && stack[len(stack)-1].contentIndent > indent {
+				stack = stack[:len(stack)-1]
+			}
+		}
+		if len(stack) > 0 {
+			top := stack[len(stack)-1]
+			top.text = append(top.text, strings.TrimSpace(line))
+			extend(lineNo)
+		}
+		sawBlank = false
+	}
+
+	items := make([]Item, 0, len(roots))
+	for _, n := range roots {
+		items = append(items, n.build())
+	}
+	return items
+}
+
+// indentWidth measures leading whitespace, counting a tab as four columns.
+func indentWidth(s string) int {
+	w := 0
+	for _, r := range s {
+		switch r {
+		case ' ':
+			w++
+		case '\t':
+			w += 4
+		default:
+			return w
+		}
+	}
+	return w
+}
@@ -0,0 +1,108 @@
+package changelog
+
+import "testing"
+
+func TestItems(t *testing.T) {
+	p := Parse(mustReadFixture(t, "comprehensive.md"))
+
+	t.Run("nested children", func(t *testing.T) {
+		entry, _ := p.Entry("1.1.0")
+		if len(entry.Items) != 1 {
+			t.Fatalf("expected 1 top-level item, got %d", len(entry.Items))
+		}
+		item := entry.Items[0]
+		if item.Text != "Multiple fixes:" {
+			t.Errorf("text = %q, want %q", item.Text, "Multiple fixes:")
+		}
+		if len(item.Children) != 2 {
+			t.Fatalf("expected 2 children, got %d", len(item.Children))
+		}
+		if item.Children[0].Text != "Fix one" || item.Children[1].Text != "Fix two" {
+			t.Errorf("children = %q, %q", item.Children[0].Text, item.Children[1].Text)
+		}
+	})
+
+	t.Run("line ranges", func(t *testing.T) {
+		entry, _ := p.Entry("1.1.0")
+		item := entry.Items[0]
+		if item.StartLine != 55 || item.EndLine != 57 {
+			t.Errorf("item lines = %d-%d, want 55-57", item.StartLine, item.EndLine)
+		}
+		child := item.Children[1]
+		if child.StartLine != 57 || child.EndLine != 57 {
+			t.Errorf("child lines = %d-%d, want 57-57", child.StartLine, child.EndLine)
+		}
+	})
+
+	t.Run("mixed markers and blank lines", func(t *testing.T) {
+		entry, _ := p.Entry("1.3.0")
+		if len(entry.Items) != 3 {
+			t.Fatalf("expected 3 top-level items, got %d", len(entry.Items))
+		}
+		if len(entry.Items[0].Children) != 2 {
+			t.Errorf("expected 2 children, got %d", len(entry.Items[0].Children))
+		}
+		if entry.Items[1].Text != "Main item with dash" {
+			t.Errorf("item[1] = %q", entry.Items[1].Text)
+		}
+		if entry.Items[2].Text != "Old API endpoint" {
+			t.Errorf("item[2] = %q", entry.Items[2].Text)
+		}
+	})
+
+	t.Run("items per section", func(t *testing.T) {
+		entry, _ := p.Entry("1.5.0-beta.2")
+		added, _ := entry.Section(CategoryAdded)
+		fixed, _ := entry.Section(CategoryFixed)
+		if len(added.Items) != 2 || len(fixed.Items) != 1 {
+			t.Fatalf("added=%d fixed=%d, want 2 and 1", len(added.Items), len(fixed.Items))
+		}
+		if fixed.Items[0].Text != "Bug fix with `inline code`" {
+			t.Errorf("fixed item = %q", fixed.Items[0].Text)
+		}
+	})
+
+	t.Run("no items", func(t *testing.T) {
+		entry, _ := p.Entry("1.4.0-rc.1")
+		if len(entry.Items) != 0 {
+			t.Errorf("expected no items, got %d", len(entry.Items))
+		}
+	})
+}
+
+func TestParseItemsContinuation(t *testing.T) {
+	lines := []string{
+		"- First item",
+		"wrapped lazily",
+		"- Second item",
+		"",
+		"  indented paragraph",
+		"  - Child",
+		"    child continued",
+		"",
+		"Trailing paragraph",
+		"+ Third",
+	}
+	items := parseItems(lines, 10)
+	if len(items) != 3 {
+		t.Fatalf("expected 3 items, got %d", len(items))
+	}
+	if items[0].Text != "First item wrapped lazily" {
+		t.Errorf("item[0] = %q", items[0].Text)
+	}
+	if items[0].StartLine != 10 || items[0].EndLine != 11 {
+		t.Errorf("item[0] lines = %d-%d, want 10-11", items[0].StartLine, items[0].EndLine)
+	}
+	if items[1].Text != "Second item indented paragraph" {
+		t.Errorf("item[1] = %q", items[1].Text)
+	}
+	if len(items[1].Children) != 1 || items[1].Children[0].Text != "Child child continued" {
+		t.Errorf("item[1] children = %+v", items[1].Children)
+	}
+	if items[1].EndLine != 16 {
+		t.Errorf("item[1] end = %d, want 16", items[1].EndLine)
+	}
+	if items[2].Text != "Third" {
+		t.Errorf("item[2] = %q", items[2].Text)
+	}
+}
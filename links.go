@@ -0,0 +1,76 @@
+package changelog
+
+import (
+	"maps"
+	"regexp"
+	"strings"
+)
+
+// linkDefinition matches a Markdown link reference definition such as
+// "[1.0.0]: https://github.com/owner/repo/compare/v0.9.0...v1.0.0".
+var linkDefinition = regexp.MustCompile(`^ {0,3}\[([^\]]+)\]:[ \t]*<?([^\s>]+)>?(?:[ \t]+["'(].*)?[ \t]*$`)
+
+// parseLinks collects every link reference definition in content, keyed by
+// label. The first definition of a label wins, as in Markdown.
+func parseLinks(content string) map[string]string {
+	links := make(map[string]string)
+	for _, line := range strings.Split(content, "\n") {
+		m := linkDefinition.FindStringSubmatch(strings.TrimRight(line, "\r"))
+		if m == nil {
+			continue
+		}
+		if _, ok := links[m[1]]; !ok {
+			links[m[1]] = m[2]
+		}
+	}
+	return links
+}
+
+// trailingLinksStart returns the offset where the block of link reference
+// definitions at the end of content[:end] begins, or end if the content
+// does not end with one. Blank lines between definitions are part of the
+// block.
+func trailingLinksStart(content string, end int) int {
+	start := end
+	pos := end
+	for pos > 0 {
+		lineStart := strings.LastIndexByte(content[:pos], '\n') + 1
+		line := strings.TrimRight(content[lineStart:pos], "\r")
+		if linkDefinition.MatchString(line) {
+			start = lineStart
+		} else if strings.TrimSpace(line) != "" {
+			break
+		}
+		if lineStart == 0 {
+			break
+		}
+		pos = lineStart - 1
+	}
+	return start
+}
+
+// linkFor finds the link for a version, trying an exact label match first
+// and then ignoring case and a leading "v".
+func linkFor(links map[string]string, version string) string {
+	if url, ok := links[version]; ok {
+		return url
+	}
+	want := trimV(strings.ToLower(version))
+	for label, url := range links {
+		if trimV(strings.ToLower(label)) == want {
+			return url
+		}
+	}
+	return ""
+}
+
+func trimV(s string) string {
+	return strings.TrimPrefix(s, "v")
+}
+
+// Links returns the link reference definitions found in the changelog,
+// keyed by label (for example "Unreleased" or "1.0.0").
+func (p *Parser) Links() map[string]string {
+	p.ensureParsed()
+	return maps.Clone(p.links)
+}
@@ -0,0 +1,63 @@
+package changelog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLinks(t *testing.T) {
+	p := Parse(mustReadFixture(t, "comprehensive.md"))
+
+	t.Run("map of definitions", func(t *testing.T) {
+		links := p.Links()
+		if len(links) != 2 {
+			t.Fatalf("expected 2 links, got %d: %v", len(links), links)
+		}
+		if links["Unreleased"] != "https://github.com/example/project/compare/v1.5.0...HEAD" {
+			t.Errorf("Unreleased link = %q", links["Unreleased"])
+		}
+	})
+
+	t.Run("exposed on entries", func(t *testing.T) {
+		entry, _ := p.Entry("Unreleased")
+		if entry.URL != "https://github.com/example/project/compare/v1.5.0...HEAD" {
+			t.Errorf("Unreleased URL = %q", entry.URL)
+		}
+		entry, _ = p.Entry("1.3.0")
+		if entry.URL != "" {
+			t.Errorf("expected no URL for 1.3.0, got %q", entry.URL)
+		}
+	})
+
+	t.Run("stripped from last entry", func(t *testing.T) {
+		entry, _ := p.Entry("1.0.0")
+		if strings.Contains(entry.Content, "compare") {
+			t.Errorf("links leaked into content: %q", entry.Content)
+		}
+		if !strings.HasSuffix(entry.Content, "- Documentation") {
+			t.Errorf("content = %q", entry.Content)
+		}
+		if len(entry.Items) != 2 {
+			t.Errorf("expected 2 items, got %d", len(entry.Items))
+		}
+	})
+
+	t.Run("case and v prefix", func(t *testing.T) {
+		p := Parse("## [1.0.0]\n\n- x\n\n[unreleased]: https://e.com/a\n[v1.0.0]: https://e.com/b \"Release\"\n")
+		entry, _ := p.Entry("1.0.0")
+		if entry.URL != "https://e.com/b" {
+			t.Errorf("URL = %q", entry.URL)
+		}
+	})
+
+	t.Run("inline definitions stay in content", func(t *testing.T) {
+		p := Parse("## [2.0.0]\n\nSee [docs].\n\n[docs]: https://e.com/docs\n\n## [1.0.0]\n\n- x\n")
+		entry, _ := p.Entry("2.0.0")
+		if !strings.Contains(entry.Content, "[docs]: https://e.com/docs") {
+			t.Errorf("content = %q", entry.Content)
+		}
+		if p.Links()["docs"] != "https://e.com/docs" {
+			t.Error("expected docs link in Links()")
+		}
+	})
+}
@@ -0,0 +1,65 @@
+package changelog
+
+import (
+	"regexp"
+	"time"
+)
+
+// orgDate matches an Org timestamp's date, with its optional day name and
+// time ("2024-03-15 Fri 10:00"), or any other header date.
+const orgDate = `\d{4}-\d{2}-\d{2}(?:[ \t]+[A-Za-z]{2,3}\.?)(?:[ \t]+\d{1,2}:\d{2})?|` + dateExpr
+
+var (
+	orgHeadline        = regexp.MustCompile(`(?m)^(\*+)[ \t]+\S`)
+	orgVersionHeadline = regexp.MustCompile(`^\*+[ \t]+(?:(?:TODO|DONE)[ \t]+)?(?:\[#[A-Z]\][ \t]+)?` +
+		`(?i:(?:version|release)[ \t]+)?v?((?:\d+[:!])?[\w.+~-]+\.[\w.+~-]+[a-zA-Z0-9]|(?i:unreleased))` +
+		`(?:[ \t]+(?:[-–—/][ \t]+)?[<\[(]?(` + orgDate + `)(?:-\d{1,2}:\d{2})?[>\])]?)?(?:[ \t]|$)`)
+	orgMarkup       = regexp.MustCompile(`(?m)^#\+[A-Za-z_]+:|^[ \t]*#\+(?i:begin)_|^[ \t]*:PROPERTIES:[ \t]*$|\[\[[^\]\n]+\](?:\[[^\]\n]*\])?\]|[<\[]\d{4}-\d{2}-\d{2} [A-Za-z]{2,3}\.?[>\] ]`)
+	orgHeadlineLine = regexp.MustCompile(`^\*+[ \t]+(.+?)(?:[ \t]+:[\w@#%:]+:)?[ \t]*$`)
+)
+
+// orgHeaders finds the version headlines of an Org-mode changelog:
+//
+//	#+TITLE: Changelog
+//
+//	* 1.2.0 <2024-03-15 Fri>
+//	** Added
+//	- New feature.
+//
+// Versions may be plain or carry an Org timestamp, active or inactive.
+// As in AsciiDoc changelogs, the headline level with the most versions
+// holds them and an entry ends at the next headline of the same or a
+// higher level.
+func orgHeaders(content string) []header {
+	return outlineHeaders(content, orgHeadline, orgVersionHeadline)
+}
+
+// looksLikeOrg reports whether content has Org version headlines and
+// Org markup, such as "#+" keywords, property drawers, links or
+// timestamps.
+func looksLikeOrg(content string) bool {
+	return orgMarkup.MatchString(content) && len(orgHeaders(content)) > 0
+}
+
+// orgHeading treats the headlines inside an entry as section headings,
+// without their tags.
+func orgHeading(lines []string, i int) (string, int, bool) {
+	if m := orgHeadlineLine.FindStringSubmatch(lines[i]); m != nil {
+		return m[1], 1, true
+	}
+	return "", 0, false
+}
+
+// renderOrgDate writes an active Org timestamp for t.
+func renderOrgDate(t time.Time) string {
+	return "<" + t.Format("2006-01-02 Mon") + ">"
+}
+
+// renderOrgHeader writes a top-level Org headline for an entry.
+func renderOrgHeader(e Entry) string {
+	header := "* " + e.Version
+	if e.Date != nil {
+		header += " " + renderOrgDate(*e.Date)
+	}
+	return header
+}
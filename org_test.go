@@ -0,0 +1,93 @@
+package changelog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOrgFormat(t *testing.T) {
+	p := Parse(mustReadFixture(t, "NEWS.org"))
+	if p.format != FormatOrg {
+		t.Fatalf("detected format %v, want org", p.format)
+	}
+	if got := strings.Join(p.Versions(), ","); got != "Unreleased,1.2.0,1.1.0" {
+		t.Fatalf("Versions() = %s", got)
+	}
+
+	entry, _ := p.Entry("1.2.0")
+	assertDate(t, entry.Date, 2024, time.March, 15)
+	if entry.RawDate != "2024-03-15 Fri" {
+		t.Errorf("RawDate = %q", entry.RawDate)
+	}
+	if len(entry.Sections) != 2 || entry.Sections[0].Name != "Added" || entry.Sections[1].Category != CategoryFixed {
+		t.Fatalf("Sections = %+v", entry.Sections)
+	}
+	if items := entry.Sections[0].Items; len(items) != 1 || len(items[0].Children) != 1 {
+		t.Errorf("Added items = %+v", items)
+	}
+
+	entry, _ = p.Entry("1.1.0")
+	assertDate(t, entry.Date, 2024, time.January, 10)
+}
+
+func TestParseFileOrgExtension(t *testing.T) {
+	// Headlines without Org markup are not detected as Org, so the
+	// extension selects the format.
+	path := filepath.Join(t.TempDir(), "CHANGELOG.org")
+	if err := os.WriteFile(path, []byte("* 1.1.0\n- Fix\n\n* 1.0.0\n- First\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	p, err := ParseFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.format != FormatOrg || strings.Join(p.Versions(), ",") != "1.1.0,1.0.0" {
+		t.Errorf("format %v, Versions() = %v", p.format, p.Versions())
+	}
+}
+
+func TestOrgHeaders(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		version string
+		date    string
+	}{
+		{"active timestamp", "* 1.2.0 <2024-03-15 Fri>\n", "1.2.0", "2024-03-15 Fri"},
+		{"timestamp with time", "* 1.2.0 <2024-03-15 Fri 10:30>\n", "1.2.0", "2024-03-15 Fri 10:30"},
+		{"inactive timestamp", "* v1.2.0 [2024-03-15 Fri]\n", "1.2.0", "2024-03-15 Fri"},
+		{"plain date", "* Version 1.2.0 (2024-03-15)\n", "1.2.0", "2024-03-15"},
+		{"todo keyword and tags", "* DONE 1.2.0 <2024-03-15 Fri> :release:\n", "1.2.0", "2024-03-15 Fri"},
+		{"no date", "* 1.2.0\n", "1.2.0", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := ParseWithFormat(tt.content, FormatOrg)
+			entry, ok := p.Entry(tt.version)
+			if !ok {
+				t.Fatalf("Versions() = %v", p.Versions())
+			}
+			if entry.RawDate != tt.date {
+				t.Errorf("RawDate = %q, want %q", entry.RawDate, tt.date)
+			}
+		})
+	}
+}
+
+func TestRenderOrg(t *testing.T) {
+	date := time.Date(2024, time.March, 15, 0, 0, 0, 0, time.UTC)
+	out := Render(FormatOrg, []Entry{{Version: "1.2.0", Date: &date, Sections: []Section{
+		{Name: "Fixed", Items: []Item{{Text: "Crash"}}},
+	}}})
+	want := "* 1.2.0 <2024-03-15 Fri>\n\n** Fixed\n\n- Crash\n"
+	if out != want {
+		t.Errorf("Render(FormatOrg) = %q, want %q", out, want)
+	}
+	entry, ok := ParseWithFormat(out, FormatOrg).Entry("1.2.0")
+	if !ok || entry.Date == nil || len(entry.Sections) != 1 {
+		t.Errorf("round trip = %+v", entry)
+	}
+}
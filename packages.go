@@ -0,0 +1,94 @@
+package changelog
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// packagePrefix matches the package name that monorepo tools put before
+// the version in headers and tags, for use at the start of a header
+// pattern's version group.
+const packagePrefix = `(?:(?:@[\w.-]+/)?[\w.-]+@v?|[A-Za-z][\w.-]*(?:/[\w.-]+)*/v?)?`
+
+// packageVersion splits the package from a scoped version:
+//
+//	@babel/core@7.24.0  lerna, changesets
+//	my-crate-v0.3.1     release-please, cargo-release
+//	tools/v1.2.0        Go modules and other path-style tags
+var packageVersion = regexp.MustCompile(`^(?:((?:@[\w.-]+/)?[\w.-]+)@v?` +
+	`|((?:@[\w.-]+/)?[A-Za-z][\w.-]*?)-v` +
+	`|([A-Za-z][\w.-]*(?:/[\w.-]+)*)/v?)(\d[\w.+~:-]*)$`)
+
+// inlineLink matches a link target directly after a header's bracketed
+// version, as in release-please's "## [1.2.0](https://...) (2024-03-15)".
+var inlineLink = regexp.MustCompile(`^\(([^)\s]+)\)`)
+
+// splitPackage splits a scoped version such as "@babel/core@7.24.0" into
+// its package and version. ok is false for versions without a package.
+func splitPackage(s string) (pkg, version string, ok bool) {
+	m := packageVersion.FindStringSubmatch(s)
+	if m == nil {
+		return "", s, false
+	}
+	return m[1] + m[2] + m[3], m[4], true
+}
+
+// packageFromURL returns the package named by the new tag of a compare
+// URL, such as "pkg" for ".../compare/pkg-v1.1.0...pkg-v1.2.0", when that
+// tag is for version.
+func packageFromURL(url, version string) string {
+	_, tags, ok := strings.Cut(url, "/compare/")
+	if !ok {
+		return ""
+	}
+	_, tag, ok := strings.Cut(tags, "...")
+	if !ok {
+		return ""
+	}
+	if pkg, v, ok := splitPackage(tag); ok && trimV(v) == trimV(version) {
+		return pkg
+	}
+	return ""
+}
+
+// headerVersion returns the version of a header and the package it belongs
+// to: the package the format recorded, or the one written before the
+// version.
+func (p *Parser) headerVersion(h header) (pkg, version string) {
+	version = p.extractGroup(h.match, p.matchGroup)
+	if h.pkg != "" {
+		return h.pkg, version
+	}
+	if pkg, v, ok := splitPackage(version); ok {
+		return pkg, v
+	}
+	return "", version
+}
+
+// Packages returns the names of the packages that entries belong to, in
+// the order they first appear. Monorepo changelogs name the package in
+// each header ("## @scope/pkg@1.2.0", "## pkg-v1.2.0", "## pkg/1.2.0"),
+// and some formats record it, such as Debian stanzas.
+func (p *Parser) Packages() []string {
+	p.ensureParsed()
+	var names []string
+	for _, ve := range p.entries {
+		if pkg := ve.entry.Package; pkg != "" && !slices.Contains(names, pkg) {
+			names = append(names, pkg)
+		}
+	}
+	return names
+}
+
+// WithPackage returns a parser for the same content restricted to the
+// entries of one package; p itself is not changed, so it can be called on
+// a parser shared between goroutines. Versions, Entry, Query, Between and
+// the other accessors of the returned parser see only that package's
+// history, so a monorepo changelog can be read one package at a time. An
+// empty name returns an unrestricted parser.
+func (p *Parser) WithPackage(name string) *Parser {
+	c := p.clone()
+	c.packageName = name
+	return c
+}
@@ -0,0 +1,113 @@
+package changelog
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestScopedHeaders(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		pkg     string
+		version string
+	}{
+		{"changesets", "## @babel/core@7.24.0\n\n- x\n", "@babel/core", "7.24.0"},
+		{"lerna", "## my-lib@1.2.0 (2024-03-15)\n\n- x\n", "my-lib", "1.2.0"},
+		{"cargo-release", "## my-crate-v0.3.1 - 2024-03-15\n\n- x\n", "my-crate", "0.3.1"},
+		{"path tag", "## tools/cmd/v1.2.0\n\n- x\n", "tools/cmd", "1.2.0"},
+		{"keep a changelog", "## [@scope/pkg@1.2.0] - 2024-03-15\n\n- x\n", "@scope/pkg", "1.2.0"},
+		{"release-please", "## [1.2.0](https://github.com/o/r/compare/pkg-v1.1.0...pkg-v1.2.0) (2024-03-15)\n\n- x\n", "pkg", "1.2.0"},
+		{"plain", "## v1.2.0\n\n- x\n", "", "1.2.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := Parse(tt.content)
+			if got := strings.Join(p.Versions(), ","); got != tt.version {
+				t.Fatalf("Versions() = %s, want %s", got, tt.version)
+			}
+			entry, _ := p.Entry(tt.version)
+			if entry.Package != tt.pkg {
+				t.Errorf("Package = %q, want %q", entry.Package, tt.pkg)
+			}
+		})
+	}
+}
+
+func TestReleasePleaseHeader(t *testing.T) {
+	content := "## [1.2.0](https://github.com/o/r/compare/v1.1.0...v1.2.0) (2024-03-15)\n\n### Features\n\n* x\n"
+	entry, ok := Parse(content).Entry("1.2.0")
+	if !ok {
+		t.Fatal("version not found")
+	}
+	assertDate(t, entry.Date, 2024, time.March, 15)
+	if entry.URL != "https://github.com/o/r/compare/v1.1.0...v1.2.0" || entry.Package != "" {
+		t.Errorf("URL = %q, Package = %q", entry.URL, entry.Package)
+	}
+
+	e := NewEditor("## [1.2.0](https://example.com)\n\n* x\n")
+	if err := e.SetDate("1.2.0", time.Date(2024, time.March, 15, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatal(err)
+	}
+	if want := "## [1.2.0](https://example.com) - 2024-03-15\n\n* x\n"; e.String() != want {
+		t.Errorf("SetDate() = %q, want %q", e.String(), want)
+	}
+}
+
+func TestWithPackage(t *testing.T) {
+	p := Parse(mustReadFixture(t, "monorepo.md"))
+	if got := strings.Join(p.Packages(), ","); got != "@acme/core,@acme/cli" {
+		t.Fatalf("Packages() = %s", got)
+	}
+	if got := strings.Join(p.Versions(), ","); got != "2.1.0,1.4.0,2.0.0,1.3.0" {
+		t.Fatalf("Versions() = %s", got)
+	}
+	if entry, ok := p.Entry("@acme/cli@1.4.0"); !ok || entry.Version != "1.4.0" {
+		t.Errorf("Entry(@acme/cli@1.4.0) = %+v, %v", entry, ok)
+	}
+
+	cli := p.WithPackage("@acme/cli")
+	if got := strings.Join(cli.Versions(), ","); got != "1.4.0,1.3.0" {
+		t.Fatalf("Versions() = %s", got)
+	}
+	if _, ok := cli.Entry("2.1.0"); ok {
+		t.Error("Entry(2.1.0) found outside the package")
+	}
+	entries, err := cli.Query(">=1.0.0")
+	if err != nil || len(entries) != 2 {
+		t.Errorf("Query() = %+v, %v", entries, err)
+	}
+
+	// The package's entries are not adjacent in the file, so Between joins
+	// them rather than taking everything in between.
+	got, ok := cli.Between("1.2.0", "1.4.0")
+	if !ok || strings.Contains(got, "@acme/core") || !strings.Contains(got, "--json") {
+		t.Errorf("Between() = %q, %v", got, ok)
+	}
+
+	if n := len(p.Versions()); n != 4 {
+		t.Errorf("Versions() of the unfiltered parser has %d versions", n)
+	}
+	if n := len(cli.WithPackage("").Versions()); n != 4 {
+		t.Errorf("Versions() after WithPackage(\"\") has %d versions", n)
+	}
+}
+
+func TestWithPackageConcurrent(t *testing.T) {
+	p := Parse(mustReadFixture(t, "monorepo.md"))
+	var wg sync.WaitGroup
+	for _, name := range []string{"@acme/core", "@acme/cli", "", "@acme/core", "@acme/cli", ""} {
+		wg.Go(func() {
+			want := map[string]int{"@acme/core": 2, "@acme/cli": 2, "": 4}[name]
+			if got := len(p.WithPackage(name).Versions()); got != want {
+				t.Errorf("WithPackage(%q).Versions() has %d versions, want %d", name, got, want)
+			}
+			if got := len(p.Versions()); got != 4 {
+				t.Errorf("Versions() has %d versions", got)
+			}
+		})
+	}
+	wg.Wait()
+}
@@ -0,0 +1,24 @@
+package changelog
+
+import "regexp"
+
+var (
+	rdocHeader      = regexp.MustCompile(`(?m)^=+[ \t]+(?i:(?:version|release)[ \t]+)?v?((?:\d+[:!])?[\w.+~-]+\.[\w.+~-]+[a-zA-Z0-9]|(?i:unreleased)\b)` + headerDate)
+	rdocHeadingLine = regexp.MustCompile(`^=+[ \t]+(.+?)[ \t]*$`)
+)
+
+// rdocHeading reports whether lines[i] is an RDoc heading (=== Name).
+func rdocHeading(lines []string, i int) (string, int, bool) {
+	if m := rdocHeadingLine.FindStringSubmatch(lines[i]); m != nil {
+		return m[1], 1, true
+	}
+	return "", 0, false
+}
+
+// renderRDocHeader writes an RDoc release heading, "=== 1.2.0 / 2024-03-15".
+func renderRDocHeader(e Entry) string {
+	if e.Date != nil {
+		return "=== " + e.Version + " / " + e.Date.Format("2006-01-02")
+	}
+	return "=== " + e.Version
+}
@@ -0,0 +1,56 @@
+package changelog
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRDocFormat(t *testing.T) {
+	content := mustReadFixture(t, "history.rdoc")
+	p := Parse(content)
+	if p.pattern != rdocHeader {
+		t.Fatal("expected rdoc pattern")
+	}
+
+	if got := strings.Join(p.Versions(), ","); got != "1.2.0,1.1.0,1.0.0" {
+		t.Fatalf("Versions() = %s", got)
+	}
+
+	t.Run("dates", func(t *testing.T) {
+		entry, _ := p.Entry("1.2.0")
+		assertDate(t, entry.Date, 2024, time.March, 15)
+		entry, _ = p.Entry("1.1.0")
+		assertDate(t, entry.Date, 2024, time.February, 1)
+	})
+
+	t.Run("hoe style items", func(t *testing.T) {
+		entry, _ := p.Entry("1.2.0")
+		if len(entry.Items) != 2 || entry.Items[0].Text != "2 major enhancements:" || len(entry.Items[0].Children) != 2 {
+			t.Errorf("Items = %+v", entry.Items)
+		}
+	})
+
+	t.Run("sections", func(t *testing.T) {
+		entry, _ := p.Entry("1.1.0")
+		if s, ok := entry.Section(CategoryFixed); !ok || len(s.Items) != 1 {
+			t.Errorf("Sections = %+v", entry.Sections)
+		}
+	})
+
+	t.Run("render round trip", func(t *testing.T) {
+		out := p.Render(FormatRDoc)
+		if !strings.HasPrefix(out, "=== 1.2.0 / 2024-03-15\n") || !strings.Contains(out, "==== Fixed\n") {
+			t.Errorf("Render() = %q", out)
+		}
+		if got := strings.Join(ParseWithFormat(out, FormatRDoc).Versions(), ","); got != "1.2.0,1.1.0,1.0.0" {
+			t.Errorf("round trip Versions() = %s", got)
+		}
+	})
+	t.Run("detects version headings", func(t *testing.T) {
+		p := Parse("= Changelog\n\n== Version 1.0.0\n\nContent")
+		if p.pattern != rdocHeader {
+			t.Error("expected rdoc pattern")
+		}
+	})
+}
@@ -0,0 +1,115 @@
+package changelog
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	rpmSection = regexp.MustCompile(`(?m)^%changelog[ \t]*$`)
+	rpmNextTag = regexp.MustCompile(`(?m)^%[a-z]\w*`)
+	rpmName    = regexp.MustCompile(`(?m)^Name:[ \t]*(\S+)`)
+	rpmHeader  = regexp.MustCompile(`(?m)^\*[ \t]+` +
+		`((?:Mon|Tue|Wed|Thu|Fri|Sat|Sun)[ \t]+[A-Z][a-z]{2}[ \t]+\d{1,2}(?:[ \t]+\d{2}:\d{2}:\d{2}[ \t]+[A-Z]+)?[ \t]+\d{4})` +
+		`[ \t]+([^\n]*?)[ \t]*$`)
+	rpmVersion = regexp.MustCompile(`^(?:\d+:)?\d[\w.+~^]*(?:-[\w.+~^]+)?`)
+)
+
+// rpmChangelog returns the bounds of the %changelog section of a spec
+// file, which runs to the next section tag. Content without a %changelog
+// line is treated as the section itself.
+func rpmChangelog(content string) (int, int) {
+	loc := rpmSection.FindStringIndex(content)
+	if loc == nil {
+		return 0, len(content)
+	}
+	start := nextLine(content, loc[1])
+	end := len(content)
+	if next := rpmNextTag.FindStringIndex(content[start:]); next != nil {
+		end = start + next[0]
+	}
+	return start, end
+}
+
+// rpmHeaders finds the entries of an RPM spec's changelog:
+//
+//	%changelog
+//	* Fri Mar 15 2024 Jane Doe <jane@example.com> - 1.2.3-1
+//	- Change.
+//
+// The version-release follows the packager, usually after " - ". Entries
+// without one are skipped. The package name comes from the spec's Name
+// tag, if present.
+func rpmHeaders(content string) []header {
+	start, end := rpmChangelog(content)
+	section := content[start:end]
+
+	var pkg string
+	if m := rpmName.FindStringSubmatch(content); m != nil {
+		pkg = m[1]
+	}
+
+	var headers []header
+	for _, m := range rpmHeader.FindAllStringSubmatchIndex(section, -1) {
+		if n := len(headers); n > 0 && headers[n-1].end == 0 {
+			headers[n-1].end = start + m[0]
+		}
+		rest := section[m[4]:m[5]]
+		author, version, offset := rpmSplit(rest)
+		if version == "" {
+			continue
+		}
+		vStart := start + m[4] + offset
+		headers = append(headers, header{
+			match:  []int{start + m[0], start + m[1], vStart, vStart + len(version), start + m[2], start + m[3]},
+			pkg:    pkg,
+			author: author,
+		})
+	}
+	if n := len(headers); n > 0 && headers[n-1].end == 0 {
+		headers[n-1].end = end
+	}
+	return headers
+}
+
+// rpmSplit separates the packager from the version-release in the text
+// after an entry's date, returning the version's offset in rest.
+func rpmSplit(rest string) (author, version string, offset int) {
+	after := 0
+	if i := strings.LastIndexByte(rest, '>'); i >= 0 {
+		after = i + 1
+	} else if strings.HasPrefix(strings.TrimLeft(rest, " \t"), "- ") {
+		// No packager: the version-release follows the date directly.
+	} else if i := strings.LastIndex(rest, " - "); i >= 0 {
+		after = i + 1
+	} else {
+		return strings.TrimSpace(rest), "", 0
+	}
+	author = strings.TrimSpace(rest[:after])
+
+	tail := rest[after:]
+	trimmed := strings.TrimLeft(tail, " \t-")
+	offset = after + len(tail) - len(trimmed)
+	version = rpmVersion.FindString(trimmed)
+	return author, version, offset
+}
+
+// noHeading is the heading recogniser for formats without sections.
+func noHeading([]string, int) (string, int, bool) {
+	return "", 0, false
+}
+
+// renderRPMHeader writes an RPM changelog entry line. RPM requires a date
+// and a version-release; entries without a date, or whose version does
+// not start with a digit (such as Unreleased), are written as they are and
+// will not parse back.
+func renderRPMHeader(e Entry) string {
+	header := "*"
+	if e.Date != nil {
+		header += " " + e.Date.Format("Mon Jan 02 2006")
+	}
+	if e.Author != "" {
+		header += " " + e.Author
+	}
+	return header + " - " + e.Version
+}
@@ -0,0 +1,113 @@
+package changelog
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRPMFormat(t *testing.T) {
+	p := Parse(mustReadFixture(t, "mytool.spec"))
+	if p.format != FormatRPM {
+		t.Fatalf("detected format %d, want FormatRPM", p.format)
+	}
+
+	// The entry without a version-release is skipped.
+	if got := strings.Join(p.Versions(), ","); got != "1.2.3-1,1:1.2.2-2,1.2.0-1" {
+		t.Fatalf("Versions() = %s", got)
+	}
+
+	t.Run("header fields", func(t *testing.T) {
+		entry, _ := p.Entry("1.2.3-1")
+		assertDate(t, entry.Date, 2024, time.March, 15)
+		if entry.Author != "Jane Doe <jane@example.com>" {
+			t.Errorf("Author = %q", entry.Author)
+		}
+		if entry.Package != "mytool" {
+			t.Errorf("Package = %q", entry.Package)
+		}
+		if len(entry.Items) != 2 || entry.Items[1].Text != "Fix crash when the config file is empty" {
+			t.Errorf("Items = %+v", entry.Items)
+		}
+	})
+
+	t.Run("packager without dash", func(t *testing.T) {
+		entry, _ := p.Entry("1:1.2.2-2")
+		assertDate(t, entry.Date, 2024, time.January, 8)
+		if entry.Author != "John Smith <john@example.org>" {
+			t.Errorf("Author = %q", entry.Author)
+		}
+		if !strings.Contains(entry.Content, "Rebuild") || strings.Contains(entry.Content, "Spec cleanup") {
+			t.Errorf("Content = %q", entry.Content)
+		}
+	})
+
+	t.Run("packager without email", func(t *testing.T) {
+		entry, _ := p.Entry("1.2.0-1")
+		if entry.Author != "Jane Doe" {
+			t.Errorf("Author = %q", entry.Author)
+		}
+	})
+
+	t.Run("sections before changelog are ignored", func(t *testing.T) {
+		entry, _ := p.Entry("1.2.0-1")
+		if entry.Content != "- Initial package" {
+			t.Errorf("Content = %q", entry.Content)
+		}
+	})
+
+	t.Run("ordering", func(t *testing.T) {
+		if got := strings.Join(p.SortedVersions(), ","); got != "1:1.2.2-2,1.2.3-1,1.2.0-1" {
+			t.Errorf("SortedVersions() = %s", got)
+		}
+	})
+}
+
+func TestRPMSectionEnd(t *testing.T) {
+	content := "%changelog\n* Fri Mar 15 2024 Jane <j@example.com> - 1.0-1\n- Change\n\n%files\n/usr/bin/x\n"
+	entry, ok := ParseWithFormat(content, FormatRPM).Entry("1.0-1")
+	if !ok || entry.Content != "- Change" {
+		t.Errorf("entry = %+v, %v", entry, ok)
+	}
+}
+
+func TestRenderRPM(t *testing.T) {
+	date := time.Date(2024, time.March, 15, 0, 0, 0, 0, time.UTC)
+	entries := []Entry{{
+		Version:  "1.2.3-1",
+		Date:     &date,
+		Author:   "Jane Doe <jane@example.com>",
+		Sections: []Section{{Name: "Fixed", Items: []Item{{Text: "Crash"}}}, {Name: "Added", Items: []Item{{Text: "Flag"}}}},
+	}}
+	out := Render(FormatRPM, entries)
+	want := "* Fri Mar 15 2024 Jane Doe <jane@example.com> - 1.2.3-1\n- Crash\n- Flag\n"
+	if out != want {
+		t.Fatalf("Render() = %q, want %q", out, want)
+	}
+	entry, _ := ParseWithFormat(out, FormatRPM).Entry("1.2.3-1")
+	assertDate(t, entry.Date, 2024, time.March, 15)
+	if len(entry.Items) != 2 {
+		t.Errorf("Items = %+v", entry.Items)
+	}
+
+	t.Run("no packager", func(t *testing.T) {
+		out := Render(FormatRPM, []Entry{{Version: "1.1.0", Date: &date, Items: []Item{{Text: "Fix"}}}})
+		if want := "* Fri Mar 15 2024 - 1.1.0\n- Fix\n"; out != want {
+			t.Fatalf("Render() = %q, want %q", out, want)
+		}
+		entry, ok := ParseWithFormat(out, FormatRPM).Entry("1.1.0")
+		if !ok || entry.Author != "" {
+			t.Errorf("Entry(1.1.0) = %+v, %v", entry, ok)
+		}
+		assertDate(t, entry.Date, 2024, time.March, 15)
+	})
+
+	t.Run("from keep a changelog", func(t *testing.T) {
+		p := Parse(mustReadFixture(t, "keep_a_changelog.md"))
+		got := ParseWithFormat(p.Render(FormatRPM), FormatRPM).Versions()
+		if want := p.Released(); !slices.Equal(got, want) {
+			t.Errorf("Versions() = %v, want %v", got, want)
+		}
+	})
+}
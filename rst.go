@@ -0,0 +1,358 @@
+package changelog
+
+import (
+	"regexp"
+	"strings"
+)
+
+// rstAdornmentChars are the punctuation characters reStructuredText
+// accepts for section over- and underlines.
+const rstAdornmentChars = "!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~"
+
+var (
+	rstVersionTitle = regexp.MustCompile(`^[ \t]*(?i:(?:version|release)[ \t]+)?v?` +
+		`((?:\d+[:!])?[\w.+~-]+\.[\w.+~-]+[a-zA-Z0-9]|(?i:unreleased))` + headerDate + `(?:[\s:,]|$)`)
+	rstChangelogDirective = regexp.MustCompile(`(?m)^\.\.[ \t]+changelog::[ \t]*$`)
+	rstChangeDirective    = regexp.MustCompile(`^([ \t]*)\.\.[ \t]+change::`)
+	rstOption             = regexp.MustCompile(`^[ \t]+:([\w-]+):[ \t]*(.*?)[ \t]*$`)
+	rstMarkup             = regexp.MustCompile("(?m)^\\.\\.[ \\t]+(?:[\\w:-]+::|_[^:\\n]+:)|>`_|:[a-z]+:`")
+)
+
+// rstTitle is a section title: a line of text with an underline and
+// optionally a matching overline.
+type rstTitle struct {
+	text  string
+	first int    // index of the first line (the overline, if any)
+	line  int    // index of the text line
+	n     int    // number of lines the title occupies
+	style string // adornment character, doubled when overlined
+}
+
+// rstAdornment reports whether line is a run of at least three of the same
+// adornment character, and returns that character.
+func rstAdornment(line string) (byte, bool) {
+	s := strings.TrimRight(line, " \t\r")
+	if len(s) < 3 || !strings.ContainsRune(rstAdornmentChars, rune(s[0])) {
+		return 0, false
+	}
+	for i := 1; i < len(s); i++ {
+		if s[i] != s[0] {
+			return 0, false
+		}
+	}
+	return s[0], true
+}
+
+// rstTitleAt reports whether a section title starts at lines[i]. Titles
+// must follow a blank line or the start of the text.
+func rstTitleAt(lines []string, i int) (rstTitle, bool) {
+	if i > 0 && strings.TrimSpace(lines[i-1]) != "" {
+		return rstTitle{}, false
+	}
+	isText := func(s string) bool {
+		_, adorned := rstAdornment(s)
+		return strings.TrimSpace(s) != "" && !adorned && !isListItem(s)
+	}
+
+	if c, ok := rstAdornment(lines[i]); ok && i+2 < len(lines) && isText(lines[i+1]) {
+		if u, ok := rstAdornment(lines[i+2]); ok && u == c {
+			return rstTitle{text: strings.TrimSpace(lines[i+1]), first: i, line: i + 1, n: 3, style: string([]byte{c, c})}, true
+		}
+	}
+	if i+1 >= len(lines) || !isText(lines[i]) || strings.TrimLeft(lines[i], " \t") != lines[i] {
+		return rstTitle{}, false
+	}
+	if c, ok := rstAdornment(lines[i+1]); ok {
+		return rstTitle{text: strings.TrimSpace(lines[i]), first: i, line: i, n: 2, style: string(c)}, true
+	}
+	return rstTitle{}, false
+}
+
+// rstHeading recognises section titles inside an RST entry body.
+func rstHeading(lines []string, i int) (string, int, bool) {
+	t, ok := rstTitleAt(lines, i)
+	return t.text, t.n, ok
+}
+
+// rstHeaders finds the version headers of a reStructuredText changelog.
+// When the file uses Sphinx ".. changelog::" directives, each directive
+// with a :version: option is an entry and :released: holds its date.
+// Otherwise section levels are numbered by the order their adornment
+// styles first appear, as docutils does, and the level with the most
+// version-like titles holds the versions. An entry ends at the next title
+// of the same or a higher level.
+func rstHeaders(content string) []header {
+	lines := strings.Split(content, "\n")
+	offsets := lineOffsets(content)
+
+	var titles []rstTitle
+	var levels []int
+	var directives []header
+	styles := map[string]int{}
+	for i := 0; i < len(offsets); i++ {
+		if rstChangelogDirective.MatchString(lines[i]) {
+			if h, ok := rstDirective(content, lines, offsets, i); ok {
+				directives = append(directives, h)
+			}
+			continue
+		}
+		t, ok := rstTitleAt(lines, i)
+		if !ok {
+			continue
+		}
+		if _, seen := styles[t.style]; !seen {
+			styles[t.style] = len(styles) + 1
+		}
+		titles = append(titles, t)
+		levels = append(levels, styles[t.style])
+		i += t.n - 1
+	}
+
+	if len(directives) > 0 {
+		for i := range directives[:len(directives)-1] {
+			directives[i].end = directives[i+1].match[0]
+		}
+		return directives
+	}
+
+	counts := map[int]int{}
+	best := 0
+	for i, t := range titles {
+		if !rstVersionTitle.MatchString(t.text) {
+			continue
+		}
+		level := levels[i]
+		counts[level]++
+		if best == 0 || counts[level] > counts[best] || (counts[level] == counts[best] && level < best) {
+			best = level
+		}
+	}
+	if best == 0 {
+		return nil
+	}
+
+	var headers []header
+	for i, t := range titles {
+		if levels[i] > best {
+			continue
+		}
+		start := offsets[t.first]
+		if n := len(headers); n > 0 && headers[n-1].end == 0 {
+			headers[n-1].end = start
+		}
+		if levels[i] != best {
+			continue
+		}
+		line := lines[t.line]
+		m := rstVersionTitle.FindStringSubmatchIndex(line)
+		if m == nil {
+			continue
+		}
+		base := offsets[t.line]
+		match := []int{start, lineEnd(content, offsets[t.first+t.n-1]), base + m[2], base + m[3], -1, -1}
+		if m[4] >= 0 {
+			match[4], match[5] = base+m[4], base+m[5]
+		}
+		headers = append(headers, header{match: match})
+	}
+	return headers
+}
+
+// rstDirective reads the options of the ".. changelog::" directive at
+// lines[i]. The header runs to the end of the option block.
+func rstDirective(content string, lines []string, offsets []int, i int) (header, bool) {
+	match := []int{offsets[i], lineEnd(content, offsets[i]), -1, -1, -1, -1}
+	for j := i + 1; j < len(offsets); j++ {
+		m := rstOption.FindStringSubmatchIndex(lines[j])
+		if m == nil {
+			break
+		}
+		match[1] = lineEnd(content, offsets[j])
+		if m[4] == m[5] {
+			continue
+		}
+		switch lines[j][m[2]:m[3]] {
+		case "version":
+			match[2], match[3] = offsets[j]+m[4], offsets[j]+m[5]
+		case "released":
+			match[4], match[5] = offsets[j]+m[4], offsets[j]+m[5]
+		}
+	}
+	return header{match: match}, match[2] >= 0
+}
+
+// parseRSTBody splits an RST entry body into sections by its titles. The
+// ".. change::" directives of a Sphinx changelog become the entry's items.
+func parseRSTBody(body string, firstLine int) ([]Section, []Item) {
+	sections, items := parseBody(body, firstLine, rstHeading)
+	if changes := rstChangeItems(strings.Split(body, "\n"), firstLine); len(changes) > 0 {
+		items = changes
+	}
+	return sections, items
+}
+
+// rstChangeItems turns each ".. change::" directive into an item whose
+// text is the directive's content, without its options, folded onto one
+// line.
+func rstChangeItems(lines []string, firstLine int) []Item {
+	var items []Item
+	for i := 0; i < len(lines); i++ {
+		m := rstChangeDirective.FindStringSubmatch(lines[i])
+		if m == nil {
+			continue
+		}
+		indent := indentWidth(m[1])
+		item := Item{StartLine: firstLine + i, EndLine: firstLine + i}
+		var text []string
+		options := true
+		j := i + 1
+		for ; j < len(lines); j++ {
+			line := lines[j]
+			if strings.TrimSpace(line) == "" {
+				options = false
+				continue
+			}
+			if indentWidth(line[:len(line)-len(strings.TrimLeft(line, " \t"))]) <= indent {
+				break
+			}
+			item.EndLine = firstLine + j
+			if options && rstOption.MatchString(line) {
+				continue
+			}
+			options = false
+			text = append(text, strings.TrimSpace(line))
+		}
+		item.Text = strings.Join(text, " ")
+		items = append(items, item)
+		i = j - 1
+	}
+	return items
+}
+
+// looksLikeRST reports whether content is a reStructuredText changelog
+// rather than plain underlined headers: it must have version titles and
+// either RST markup or titles the underline format does not use, such as
+// overlined ones or other adornment characters.
+func looksLikeRST(content string) bool {
+	if rstChangelogDirective.MatchString(content) {
+		return true
+	}
+	if len(rstHeaders(content)) == 0 {
+		return false
+	}
+	if rstMarkup.MatchString(content) {
+		return true
+	}
+	lines := strings.Split(content, "\n")
+	for i := range lines {
+		if t, ok := rstTitleAt(lines, i); ok && t.style != "=" && t.style != "-" {
+			return true
+		}
+	}
+	return false
+}
+
+// lineStart returns the offset of the first byte of the line containing
+// offset.
+func lineStart(s string, offset int) int {
+	return strings.LastIndexByte(s[:offset], '\n') + 1
+}
+
+// rstAdornments records how an RST changelog adorns its version and
+// section titles, so that edits can match the file.
+type rstAdornments struct {
+	version  byte
+	overline bool
+	section  byte
+}
+
+func (p *Parser) rstAdornments() rstAdornments {
+	a := rstAdornments{version: '=', section: '-'}
+	lines := strings.Split(p.content, "\n")
+	used := map[byte]bool{}
+	for i := 0; i < len(lines); i++ {
+		if t, ok := rstTitleAt(lines, i); ok {
+			used[t.style[0]] = true
+			i += t.n - 1
+		}
+	}
+
+	p.ensureParsed()
+	if len(p.entries) > 0 {
+		m := p.entries[0].match
+		if c, ok := rstAdornment(p.content[lineStart(p.content, m[1]):m[1]]); ok {
+			a.version = c
+			a.overline = m[0] != lineStart(p.content, m[2])
+		}
+	}
+	for _, ve := range p.entries {
+		for _, s := range ve.entry.Sections {
+			if c, ok := rstAdornment(lines[s.StartLine]); ok {
+				a.section = c
+				return a
+			}
+			if c, ok := rstAdornment(lines[s.StartLine+1]); ok {
+				a.section = c
+				return a
+			}
+		}
+	}
+	for _, c := range []byte("-~^\"'+*#") {
+		if !used[c] && c != a.version {
+			a.section = c
+			break
+		}
+	}
+	return a
+}
+
+// restyle rewrites the adornments of text rendered in FormatRST, where
+// versions are underlined with "=" and sections with "-", to the file's.
+func (a rstAdornments) restyle(text string) string {
+	lines := strings.Split(text, "\n")
+	var out []string
+	for i, line := range lines {
+		c, ok := rstAdornment(line)
+		if !ok || i == 0 {
+			out = append(out, line)
+			continue
+		}
+		switch c {
+		case '=':
+			adornment := strings.Repeat(string(a.version), len(line))
+			if a.overline {
+				out = append(out[:len(out)-1], adornment, lines[i-1])
+			}
+			out = append(out, adornment)
+		case '-':
+			out = append(out, strings.Repeat(string(a.section), len(line)))
+		default:
+			out = append(out, line)
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// fitRSTAdornment lengthens the underline, and overline if any, of the RST
+// title on the line containing offset so that they cover the title text.
+func fitRSTAdornment(content string, offset int) string {
+	start := lineStart(content, offset)
+	end := lineEnd(content, offset)
+	width := len(strings.TrimRight(content[start:end], " \t\r"))
+
+	if end < len(content) {
+		next := end + 1
+		under := content[next:lineEnd(content, next)]
+		if c, ok := rstAdornment(under); ok && len(under) < width {
+			content = content[:next] + strings.Repeat(string(c), width) + content[next+len(under):]
+		}
+	}
+	if start > 0 {
+		prev := lineStart(content, start-1)
+		over := content[prev : start-1]
+		if c, ok := rstAdornment(over); ok && len(over) < width {
+			content = content[:prev] + strings.Repeat(string(c), width) + content[start-1:]
+		}
+	}
+	return content
+}
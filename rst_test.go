@@ -0,0 +1,181 @@
+package changelog
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRSTFormat(t *testing.T) {
+	content := mustReadFixture(t, "changes.rst")
+	p := Parse(content)
+	if p.format != FormatRST {
+		t.Fatalf("detected format %d, want FormatRST", p.format)
+	}
+
+	if got := strings.Join(p.Versions(), ","); got != "Unreleased,1.2.0,1.1.0,1.0.0" {
+		t.Fatalf("Versions() = %s", got)
+	}
+
+	t.Run("sections", func(t *testing.T) {
+		entry, _ := p.Entry("1.2.0")
+		assertDate(t, entry.Date, 2024, time.March, 15)
+		if len(entry.Sections) != 2 {
+			t.Fatalf("expected 2 sections, got %d", len(entry.Sections))
+		}
+		added, ok := entry.Section(CategoryAdded)
+		if !ok || len(added.Items) != 2 || added.Items[0].Text != "New ``--strict`` flag." {
+			t.Errorf("Added = %+v", added)
+		}
+		if entry.Sections[1].Category != CategoryFixed {
+			t.Errorf("second section = %q", entry.Sections[1].Name)
+		}
+	})
+
+	t.Run("date with ordinal", func(t *testing.T) {
+		entry, _ := p.Entry("1.1.0")
+		assertDate(t, entry.Date, 2024, time.February, 1)
+		if entry.Content != "* Faster startup." {
+			t.Errorf("Content = %q", entry.Content)
+		}
+	})
+
+	t.Run("higher level title ends entry", func(t *testing.T) {
+		entry, _ := p.Entry("1.0.0")
+		if entry.Content != "Initial release." {
+			t.Errorf("Content = %q", entry.Content)
+		}
+	})
+
+	t.Run("unreleased", func(t *testing.T) {
+		entry, ok := p.Unreleased()
+		if !ok || len(entry.Items) != 1 {
+			t.Errorf("Unreleased() = %+v, %v", entry, ok)
+		}
+	})
+}
+
+func TestRSTAdornmentLevels(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			"overlined versions",
+			"####\nNews\n####\n\n*****\n2.0.0\n*****\n\nTwo\n\n*****\n1.0.0\n*****\n\nOne\n",
+			"2.0.0,1.0.0",
+		},
+		{
+			"versions below the document title",
+			"Changes\n^^^^^^^\n\n2.0.0\n~~~~~\n\nSub\n===\n\nTwo\n\n1.0.0\n~~~~~\n\nOne\n",
+			"2.0.0,1.0.0",
+		},
+		{
+			"versions at the deeper level",
+			"Release 2\n=========\n\n2.1.0\n-----\n\nA\n\n2.0.0\n-----\n\nB\n\nRelease 1\n=========\n\n1.0.0\n-----\n\nC\n",
+			"2.1.0,2.0.0,1.0.0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := ParseWithFormat(tt.content, FormatRST)
+			if got := strings.Join(p.Versions(), ","); got != tt.want {
+				t.Errorf("Versions() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("sections use deeper levels", func(t *testing.T) {
+		p := ParseWithFormat("Changes\n^^^^^^^\n\n2.0.0\n~~~~~\n\nSub\n===\n\n- Two\n", FormatRST)
+		entry, _ := p.Entry("2.0.0")
+		if len(entry.Sections) != 1 || entry.Sections[0].Name != "Sub" || len(entry.Sections[0].Items) != 1 {
+			t.Errorf("Sections = %+v", entry.Sections)
+		}
+	})
+}
+
+func TestRSTChangelogDirective(t *testing.T) {
+	content := `Changelog
+=========
+
+.. changelog::
+    :version: 1.2.0
+    :released: March 15, 2024
+
+    .. change::
+        :tags: bug, orm
+        :tickets: 1234
+
+        Fixed a crash when the session
+        was closed twice.
+
+    .. change::
+        :tags: feature
+
+        Added ` + "``Session.refresh()``" + `.
+
+.. changelog::
+    :version: 1.1.0
+    :released:
+
+    .. change::
+        :tags: bug
+
+        Older fix.
+`
+	p := Parse(content)
+	if p.format != FormatRST {
+		t.Fatalf("detected format %d, want FormatRST", p.format)
+	}
+	if got := strings.Join(p.Versions(), ","); got != "1.2.0,1.1.0" {
+		t.Fatalf("Versions() = %s", got)
+	}
+
+	entry, _ := p.Entry("1.2.0")
+	assertDate(t, entry.Date, 2024, time.March, 15)
+	if len(entry.Items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(entry.Items))
+	}
+	if entry.Items[0].Text != "Fixed a crash when the session was closed twice." {
+		t.Errorf("Items[0].Text = %q", entry.Items[0].Text)
+	}
+	if entry.Items[0].StartLine != 7 || entry.Items[0].EndLine != 12 {
+		t.Errorf("Items[0] lines = %d-%d", entry.Items[0].StartLine, entry.Items[0].EndLine)
+	}
+
+	older, _ := p.Entry("1.1.0")
+	if older.Date != nil || len(older.Items) != 1 || older.Items[0].Text != "Older fix." {
+		t.Errorf("1.1.0 = %+v", older)
+	}
+}
+
+func TestRSTDetection(t *testing.T) {
+	// Plain "=" and "-" underlines without RST markup stay in the
+	// underline format.
+	if p := Parse(mustReadFixture(t, "underline.md")); p.format != FormatUnderline {
+		t.Errorf("underline fixture detected as %d", p.format)
+	}
+	if p := Parse("1.0.0\n~~~~~\n\nFirst\n"); p.format != FormatRST {
+		t.Errorf("tilde underline detected as %d", p.format)
+	}
+}
+
+func TestRenderRST(t *testing.T) {
+	date := time.Date(2024, time.March, 15, 0, 0, 0, 0, time.UTC)
+	entries := []Entry{{
+		Version:  "1.2.0",
+		Date:     &date,
+		Sections: []Section{{Category: CategoryAdded, Items: []Item{{Text: "Feature"}}}},
+	}}
+	out := Render(FormatRST, entries)
+	want := "1.2.0 (2024-03-15)\n==================\n\nAdded\n-----\n\n- Feature\n"
+	if out != want {
+		t.Fatalf("Render() = %q, want %q", out, want)
+	}
+
+	entry, _ := ParseWithFormat(out, FormatRST).Entry("1.2.0")
+	if s, ok := entry.Section(CategoryAdded); !ok || len(s.Items) != 1 {
+		t.Errorf("round trip lost the section: %+v", entry.Sections)
+	}
+}
@@ -0,0 +1,568 @@
+package changelog
+
+import (
+	"cmp"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Scheme defines how the version strings of an ecosystem are validated
+// and ordered. Parsers use a default for their format (see
+// Parser.WithScheme) unless another scheme is selected; ordering, Query and
+// Between all follow it.
+type Scheme interface {
+	// Name identifies the scheme, for example "semver" or "pep440".
+	Name() string
+
+	// Valid reports whether version is valid in this scheme.
+	Valid(version string) bool
+
+	// Compare orders two valid versions, returning -1, 0 or +1.
+	Compare(a, b string) int
+
+	// Release returns the leading numeric release components of a valid
+	// version ([1 2 3] for "1.2.3-rc.1"). Constraints use it for partial
+	// versions, wildcards and the ~ and ^ operators.
+	Release(version string) []uint64
+}
+
+// Built-in version schemes.
+var (
+	SemVer Scheme = semverScheme{} // Semantic Versioning 2.0: 1.2.3-rc.1+build
+	PEP440 Scheme = pep440Scheme{} // Python: 1!2.0rc1, 1.0.post1, 1.0.dev3
+	Maven  Scheme = mavenScheme{}  // Maven ComparableVersion: 1.0-SNAPSHOT, 2.0-beta-1
+	CalVer Scheme = calverScheme{} // Calendar versions: 2024.03.1, 24.04
+	Debian Scheme = debianScheme{} // Debian/dpkg: 1:2.3-4, 1.0~rc1-1
+	PVP    Scheme = pvpScheme{}    // Haskell Package Versioning Policy: 1.2.3.4
+)
+
+// WithScheme returns a parser for the same content that uses s for
+// ordering, Query and Between; p itself is not changed. Debian and RPM
+// changelogs default to the Debian scheme, whose epoch:version-release
+// ordering matches rpm for common versions, GNU ChangeLogs (versioned by
+// date) to CalVer, Cabal changelogs to PVP, and everything else to SemVer.
+func (p *Parser) WithScheme(s Scheme) *Parser {
+	c := p.clone()
+	c.scheme = s
+	return c
+}
+
+func (p *Parser) versionScheme() Scheme {
+	if p.scheme == nil {
+		switch p.format {
+		case FormatDebian, FormatRPM:
+			return Debian
+		case FormatGNUChangeLog:
+			return CalVer
+		case FormatCabal:
+			return PVP
+		}
+		return SemVer
+	}
+	return p.scheme
+}
+
+// SortVersionStrings sorts valid versions of the given scheme in
+// ascending order. Invalid versions are moved to the end, keeping their
+// relative order.
+func SortVersionStrings(versions []string, scheme Scheme) {
+	slices.SortStableFunc(versions, func(a, b string) int {
+		av, bv := scheme.Valid(a), scheme.Valid(b)
+		switch {
+		case !av && !bv:
+			return 0
+		case !av:
+			return 1
+		case !bv:
+			return -1
+		}
+		return scheme.Compare(a, b)
+	})
+}
+
+// leadingRelease parses the dotted run of numbers at the start of s.
+func leadingRelease(s string) []uint64 {
+	var release []uint64
+	for _, part := range strings.Split(s, ".") {
+		end := strings.IndexFunc(part, func(r rune) bool { return r < '0' || r > '9' })
+		if end == 0 {
+			break
+		}
+		digits := part
+		if end > 0 {
+			digits = part[:end]
+		}
+		n, err := strconv.ParseUint(digits, 10, 64)
+		if err != nil {
+			break
+		}
+		release = append(release, n)
+		if end > 0 {
+			break
+		}
+	}
+	return release
+}
+
+// compareNumbers compares two release lists, padding the shorter with
+// zeros.
+func compareNumbers(a, b []uint64) int {
+	for i := 0; i < max(len(a), len(b)); i++ {
+		var x, y uint64
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if c := cmp.Compare(x, y); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+type semverScheme struct{}
+
+func (semverScheme) Name() string { return "semver" }
+
+func (semverScheme) Valid(version string) bool {
+	_, err := ParseVersion(version)
+	return err == nil
+}
+
+func (semverScheme) Compare(a, b string) int {
+	av, _ := ParseVersion(a)
+	bv, _ := ParseVersion(b)
+	return av.Compare(bv)
+}
+
+func (semverScheme) Release(version string) []uint64 {
+	v, _ := ParseVersion(version)
+	return []uint64{v.Major, v.Minor, v.Patch}
+}
+
+type pep440Scheme struct{}
+
+var pep440Pattern = regexp.MustCompile(`(?i)^v?(?:(\d+)!)?(\d+(?:\.\d+)*)` +
+	`(?:[-_.]?(a|b|c|rc|alpha|beta|pre|preview)[-_.]?(\d+)?)?` +
+	`(?:-(\d+)|[-_.]?(post|rev|r)[-_.]?(\d+)?)?` +
+	`(?:[-_.]?(dev)[-_.]?(\d+)?)?` +
+	`(?:\+([a-z0-9]+(?:[-_.][a-z0-9]+)*))?$`)
+
+type pep440Version struct {
+	epoch   uint64
+	release []uint64
+	pre     [2]uint64 // phase rank and number; see parsePEP440
+	post    [2]uint64
+	dev     [2]uint64
+	local   string
+}
+
+// parsePEP440 turns a version into a sort key following the ordering in
+// PEP 440: developmental releases sort before pre-releases, which sort
+// before the final release, which sorts before post-releases.
+func parsePEP440(s string) (pep440Version, bool) {
+	m := pep440Pattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return pep440Version{}, false
+	}
+	num := func(s string) uint64 {
+		n, _ := strconv.ParseUint(s, 10, 64)
+		return n
+	}
+
+	v := pep440Version{epoch: num(m[1]), release: leadingRelease(m[2]), local: strings.ToLower(m[10])}
+	switch strings.ToLower(m[3]) {
+	case "":
+		v.pre = [2]uint64{4, 0}
+		if m[5] == "" && m[6] == "" && m[8] != "" {
+			v.pre = [2]uint64{0, 0} // 1.0.dev1 sorts before 1.0a1
+		}
+	case "a", "alpha":
+		v.pre = [2]uint64{1, num(m[4])}
+	case "b", "beta":
+		v.pre = [2]uint64{2, num(m[4])}
+	default:
+		v.pre = [2]uint64{3, num(m[4])}
+	}
+	switch {
+	case m[5] != "":
+		v.post = [2]uint64{1, num(m[5])}
+	case m[6] != "":
+		v.post = [2]uint64{1, num(m[7])}
+	}
+	v.dev = [2]uint64{1, 0}
+	if m[8] != "" {
+		v.dev = [2]uint64{0, num(m[9])}
+	}
+	return v, true
+}
+
+func (pep440Scheme) Name() string { return "pep440" }
+
+func (pep440Scheme) Valid(version string) bool {
+	_, ok := parsePEP440(version)
+	return ok
+}
+
+func (pep440Scheme) Compare(a, b string) int {
+	av, _ := parsePEP440(a)
+	bv, _ := parsePEP440(b)
+	return cmp.Or(
+		cmp.Compare(av.epoch, bv.epoch),
+		compareNumbers(av.release, bv.release),
+		slices.Compare(av.pre[:], bv.pre[:]),
+		slices.Compare(av.post[:], bv.post[:]),
+		slices.Compare(av.dev[:], bv.dev[:]),
+		compareLocal(av.local, bv.local),
+	)
+}
+
+// compareLocal orders PEP 440 local version labels: a version without a
+// label sorts first, then labels compare segment by segment, with numeric
+// segments above alphanumeric ones and a longer label winning a tie.
+func compareLocal(a, b string) int {
+	if a == "" || b == "" {
+		return cmp.Compare(len(a), len(b))
+	}
+	split := func(s string) []string {
+		return strings.FieldsFunc(s, func(r rune) bool { return r == '.' || r == '-' || r == '_' })
+	}
+	as, bs := split(a), split(b)
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aErr := strconv.ParseUint(as[i], 10, 64)
+		bn, bErr := strconv.ParseUint(bs[i], 10, 64)
+		var c int
+		switch {
+		case aErr == nil && bErr == nil:
+			c = cmp.Compare(an, bn)
+		case aErr == nil:
+			c = 1
+		case bErr == nil:
+			c = -1
+		default:
+			c = strings.Compare(as[i], bs[i])
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(len(as), len(bs))
+}
+
+func (pep440Scheme) Release(version string) []uint64 {
+	v, _ := parsePEP440(version)
+	return v.release
+}
+
+type mavenScheme struct{}
+
+// mavenQualifiers ranks the well-known Maven qualifiers. Unknown
+// qualifiers sort after all of them, alphabetically.
+var mavenQualifiers = map[string]int{
+	"alpha":     0,
+	"a":         0,
+	"beta":      1,
+	"b":         1,
+	"milestone": 2,
+	"m":         2,
+	"rc":        3,
+	"cr":        3,
+	"snapshot":  4,
+	"":          5,
+	"ga":        5,
+	"final":     5,
+	"release":   5,
+	"sp":        6,
+}
+
+type mavenItem struct {
+	number    uint64
+	qualifier string
+	isNumber  bool
+}
+
+// mavenItems splits a version into numeric and qualifier items on dots,
+// hyphens and transitions between digits and letters, then drops trailing
+// items equivalent to the release ("1.0.0" == "1" == "1-ga").
+func mavenItems(s string) []mavenItem {
+	var items []mavenItem
+	var buf strings.Builder
+	flush := func() {
+		if buf.Len() == 0 {
+			return
+		}
+		tok := buf.String()
+		buf.Reset()
+		if n, err := strconv.ParseUint(tok, 10, 64); err == nil {
+			items = append(items, mavenItem{number: n, isNumber: true})
+		} else {
+			items = append(items, mavenItem{qualifier: tok})
+		}
+	}
+	prevDigit := false
+	for i, r := range strings.ToLower(strings.TrimSpace(s)) {
+		if r == '.' || r == '-' || r == '_' {
+			flush()
+			continue
+		}
+		digit := unicode.IsDigit(r)
+		if i > 0 && buf.Len() > 0 && digit != prevDigit {
+			flush()
+		}
+		prevDigit = digit
+		buf.WriteRune(r)
+	}
+	flush()
+
+	for len(items) > 0 && items[len(items)-1].isNull() {
+		items = items[:len(items)-1]
+	}
+	return items
+}
+
+func (it mavenItem) isNull() bool {
+	if it.isNumber {
+		return it.number == 0
+	}
+	rank, ok := mavenQualifiers[it.qualifier]
+	return ok && rank == mavenQualifiers[""]
+}
+
+func (it mavenItem) compare(o mavenItem) int {
+	switch {
+	case it.isNumber && o.isNumber:
+		return cmp.Compare(it.number, o.number)
+	case it.isNumber:
+		return 1
+	case o.isNumber:
+		return -1
+	}
+	ar, aok := mavenQualifiers[it.qualifier]
+	br, bok := mavenQualifiers[o.qualifier]
+	switch {
+	case aok && bok:
+		return cmp.Compare(ar, br)
+	case aok:
+		return -1
+	case bok:
+		return 1
+	}
+	return strings.Compare(it.qualifier, o.qualifier)
+}
+
+func (mavenScheme) Name() string { return "maven" }
+
+var mavenPattern = regexp.MustCompile(`^v?\d+(?:[.\-_]?[0-9A-Za-z]+)*$`)
+
+func (mavenScheme) Valid(version string) bool {
+	return mavenPattern.MatchString(strings.TrimSpace(version))
+}
+
+func (mavenScheme) Compare(a, b string) int {
+	ai, bi := mavenItems(strings.TrimPrefix(a, "v")), mavenItems(strings.TrimPrefix(b, "v"))
+	null := mavenItem{isNumber: true}
+	for i := 0; i < max(len(ai), len(bi)); i++ {
+		x, y := null, null
+		if i < len(ai) {
+			x = ai[i]
+		}
+		if i < len(bi) {
+			y = bi[i]
+		}
+		// A missing item counts as the release: numbers pad with zero
+		// and qualifiers compare against "".
+		if i >= len(ai) && !y.isNumber {
+			x = mavenItem{}
+		}
+		if i >= len(bi) && !x.isNumber {
+			y = mavenItem{}
+		}
+		if c := x.compare(y); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func (mavenScheme) Release(version string) []uint64 {
+	return leadingRelease(strings.TrimPrefix(version, "v"))
+}
+
+type calverScheme struct{}
+
+var calverPattern = regexp.MustCompile(`^v?(\d{2}|\d{4})((?:[._-]\d+)+)(?:[-_.+]?([A-Za-z][0-9A-Za-z.\-]*))?$`)
+
+func parseCalVer(s string) ([]uint64, string, bool) {
+	m := calverPattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return nil, "", false
+	}
+	rest := strings.NewReplacer("-", ".", "_", ".").Replace(m[2])
+	return leadingRelease(m[1] + rest), m[3], true
+}
+
+func (calverScheme) Name() string { return "calver" }
+
+func (calverScheme) Valid(version string) bool {
+	_, _, ok := parseCalVer(version)
+	return ok
+}
+
+// Compare orders calendar versions by their numeric components. A
+// modifier such as "-beta" marks a pre-release, sorting before the same
+// version without one.
+func (calverScheme) Compare(a, b string) int {
+	ar, am, _ := parseCalVer(a)
+	br, bm, _ := parseCalVer(b)
+	if c := compareNumbers(ar, br); c != 0 {
+		return c
+	}
+	switch {
+	case am == bm:
+		return 0
+	case am == "":
+		return 1
+	case bm == "":
+		return -1
+	}
+	return strings.Compare(am, bm)
+}
+
+func (calverScheme) Release(version string) []uint64 {
+	r, _, _ := parseCalVer(version)
+	return r
+}
+
+type pvpScheme struct{}
+
+var pvpPattern = regexp.MustCompile(`^v?\d+(?:\.\d+)*$`)
+
+func (pvpScheme) Name() string { return "pvp" }
+
+func (pvpScheme) Valid(version string) bool {
+	return pvpPattern.MatchString(strings.TrimSpace(version))
+}
+
+// Compare orders PVP versions component by component, as Data.Version
+// does: a version that is a prefix of another sorts first, so 1.2 is
+// older than 1.2.0.
+func (pvpScheme) Compare(a, b string) int {
+	return slices.Compare(pvpRelease(a), pvpRelease(b))
+}
+
+func (pvpScheme) Release(version string) []uint64 {
+	return pvpRelease(version)
+}
+
+func pvpRelease(s string) []uint64 {
+	return leadingRelease(strings.TrimPrefix(strings.TrimSpace(s), "v"))
+}
+
+type debianScheme struct{}
+
+var debianPattern = regexp.MustCompile(`^(?:(\d+):)?([0-9][A-Za-z0-9.+~-]*)$`)
+
+// splitDebian splits a Debian version into epoch, upstream version and
+// revision. The revision follows the last hyphen.
+func splitDebian(s string) (uint64, string, string, bool) {
+	m := debianPattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return 0, "", "", false
+	}
+	epoch, _ := strconv.ParseUint(m[1], 10, 64)
+	upstream, revision := m[2], ""
+	if i := strings.LastIndexByte(upstream, '-'); i >= 0 {
+		upstream, revision = upstream[:i], upstream[i+1:]
+	}
+	return epoch, upstream, revision, upstream != ""
+}
+
+func (debianScheme) Name() string { return "debian" }
+
+func (debianScheme) Valid(version string) bool {
+	_, _, _, ok := splitDebian(version)
+	return ok
+}
+
+func (debianScheme) Compare(a, b string) int {
+	ae, au, ar, _ := splitDebian(a)
+	be, bu, br, _ := splitDebian(b)
+	return cmp.Or(
+		cmp.Compare(ae, be),
+		sign(verrevcmp(au, bu)),
+		sign(verrevcmp(ar, br)),
+	)
+}
+
+func (debianScheme) Release(version string) []uint64 {
+	_, upstream, _, _ := splitDebian(version)
+	return leadingRelease(upstream)
+}
+
+// verrevcmp is dpkg's comparison of upstream versions and revisions:
+// alternating runs of non-digits, compared character by character with
+// "~" sorting before everything (even the end of the string) and letters
+// before other characters, and runs of digits, compared numerically.
+func verrevcmp(a, b string) int {
+	order := func(s string, i int) int {
+		if i >= len(s) {
+			return 0
+		}
+		c := s[i]
+		switch {
+		case c >= '0' && c <= '9':
+			return 0
+		case (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z'):
+			return int(c)
+		case c == '~':
+			return -1
+		}
+		return int(c) + 256
+	}
+	isDigit := func(s string, i int) bool { return i < len(s) && s[i] >= '0' && s[i] <= '9' }
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		for (i < len(a) && !isDigit(a, i)) || (j < len(b) && !isDigit(b, j)) {
+			ac, bc := order(a, i), order(b, j)
+			if ac != bc {
+				return ac - bc
+			}
+			i++
+			j++
+		}
+		for isDigit(a, i) && a[i] == '0' {
+			i++
+		}
+		for isDigit(b, j) && b[j] == '0' {
+			j++
+		}
+		firstDiff := 0
+		for isDigit(a, i) && isDigit(b, j) {
+			if firstDiff == 0 {
+				firstDiff = int(a[i]) - int(b[j])
+			}
+			i++
+			j++
+		}
+		if isDigit(a, i) {
+			return 1
+		}
+		if isDigit(b, j) {
+			return -1
+		}
+		if firstDiff != 0 {
+			return firstDiff
+		}
+	}
+	return 0
+}
+
+func sign(n int) int {
+	return cmp.Compare(n, 0)
+}
@@ -0,0 +1,196 @@
+package changelog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSchemeOrdering(t *testing.T) {
+	// Each list is in ascending order.
+	tests := []struct {
+		scheme  Scheme
+		ordered []string
+	}{
+		{SemVer, []string{"1.0.0-alpha", "1.0.0-beta.2", "1.0.0-beta.11", "1.0.0", "1.2.0", "1.10.0"}},
+		{PEP440, []string{
+			"1.0.dev1", "1.0a1", "1.0a2.dev1", "1.0a2", "1.0b1", "1.0rc1", "1.0", "1.0+local.1",
+			"1.0.post1.dev1", "1.0.post1", "1.1", "2.0rc1", "2.0", "1!0.5",
+		}},
+		{Maven, []string{"1.0-alpha-1", "1.0-beta", "1.0-M1", "1.0-RC1", "1.0-SNAPSHOT", "1.0", "1.0-sp1", "1.0.1", "1.1"}},
+		{CalVer, []string{"23.10", "2023.12.1", "2024.01.0-beta", "2024.01.0", "2024.3.1", "2024.03.2", "2024.10.0"}},
+		{Debian, []string{"1.0~rc1-1", "1.0-1", "1.0-1ubuntu1", "1.0-2", "1.0.1-1", "2.3-4", "1:0.9-1", "1:2.3-4"}},
+		{PVP, []string{"0.9.9.9", "1", "1.2", "1.2.0", "1.2.0.1", "1.10"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.scheme.Name(), func(t *testing.T) {
+			for _, v := range tt.ordered {
+				if !tt.scheme.Valid(v) {
+					t.Errorf("%s should be valid", v)
+				}
+			}
+			for i := 0; i+1 < len(tt.ordered); i++ {
+				a, b := tt.ordered[i], tt.ordered[i+1]
+				if got := tt.scheme.Compare(a, b); got != -1 {
+					t.Errorf("Compare(%s, %s) = %d, want -1", a, b, got)
+				}
+				if got := tt.scheme.Compare(b, a); got != 1 {
+					t.Errorf("Compare(%s, %s) = %d, want 1", b, a, got)
+				}
+			}
+		})
+	}
+}
+
+func TestSchemeEquivalence(t *testing.T) {
+	tests := []struct {
+		scheme Scheme
+		a, b   string
+	}{
+		{PEP440, "1.0", "1.0.0"},
+		{PEP440, "1.0-1", "1.0.post1"},
+		{PEP440, "2.0RC1", "2.0rc1"},
+		{Maven, "1.0", "1"},
+		{Maven, "1.0.0", "1-ga"},
+		{Maven, "1.0-final", "1.0"},
+		{Debian, "1.0-1", "0:1.0-1"},
+		{Debian, "1.01", "1.1"},
+		{CalVer, "2024.3.1", "2024.03.01"},
+	}
+	for _, tt := range tests {
+		if got := tt.scheme.Compare(tt.a, tt.b); got != 0 {
+			t.Errorf("%s: Compare(%s, %s) = %d, want 0", tt.scheme.Name(), tt.a, tt.b, got)
+		}
+	}
+}
+
+func TestSchemeValid(t *testing.T) {
+	tests := []struct {
+		scheme  Scheme
+		invalid []string
+	}{
+		{SemVer, []string{"1.0.post1", "Unreleased"}},
+		{PEP440, []string{"1.0-SNAPSHOT", "Unreleased", "1.0+"}},
+		{Maven, []string{"Unreleased", "v"}},
+		{CalVer, []string{"1.2.3", "2024", "Unreleased"}},
+		{Debian, []string{"Unreleased", "a1.0", "1:"}},
+		{PVP, []string{"1.2-rc1", "Unreleased", "1..2"}},
+	}
+	for _, tt := range tests {
+		for _, v := range tt.invalid {
+			if tt.scheme.Valid(v) {
+				t.Errorf("%s: %q should be invalid", tt.scheme.Name(), v)
+			}
+		}
+	}
+}
+
+func TestSchemeRelease(t *testing.T) {
+	tests := []struct {
+		scheme  Scheme
+		version string
+		want    []uint64
+	}{
+		{SemVer, "1.2.3-rc.1", []uint64{1, 2, 3}},
+		{PEP440, "1!2.0rc1", []uint64{2, 0}},
+		{Maven, "1.0-SNAPSHOT", []uint64{1, 0}},
+		{CalVer, "2024.03.1", []uint64{2024, 3, 1}},
+		{Debian, "1:2.3-4", []uint64{2, 3}},
+		{PVP, "1.2.3.4", []uint64{1, 2, 3, 4}},
+	}
+	for _, tt := range tests {
+		got := tt.scheme.Release(tt.version)
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: Release(%s) = %v, want %v", tt.scheme.Name(), tt.version, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s: Release(%s) = %v, want %v", tt.scheme.Name(), tt.version, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestSortVersionStrings(t *testing.T) {
+	versions := []string{"2.0", "Unreleased", "1.0.post1", "2.0rc1", "1.0"}
+	SortVersionStrings(versions, PEP440)
+	if got := strings.Join(versions, ","); got != "1.0,1.0.post1,2.0rc1,2.0,Unreleased" {
+		t.Errorf("sorted = %s", got)
+	}
+}
+
+func TestParserWithScheme(t *testing.T) {
+	content := "## [Unreleased]\n\n## [2.0rc1]\n\nCandidate\n\n## [1.0.post1]\n\nPost\n\n## [2.0]\n\nFinal\n\n## [1.0]\n\nFirst\n"
+	p := Parse(content).WithScheme(PEP440)
+
+	t.Run("sorted versions", func(t *testing.T) {
+		got := strings.Join(p.SortedVersions(), ",")
+		if got != "Unreleased,2.0,2.0rc1,1.0.post1,1.0" {
+			t.Errorf("SortedVersions() = %s", got)
+		}
+	})
+
+	t.Run("query", func(t *testing.T) {
+		entries, err := p.Query(">1.0, <2.0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, e := range entries {
+			got = append(got, e.Version)
+		}
+		if strings.Join(got, ",") != "2.0rc1,1.0.post1" {
+			t.Errorf("Query() = %v", got)
+		}
+	})
+
+	t.Run("between with missing endpoints", func(t *testing.T) {
+		result, ok := p.Between("1.0.post0", "2.0rc2")
+		if !ok {
+			t.Fatal("expected result")
+		}
+		if !strings.Contains(result, "Candidate") || !strings.Contains(result, "Post") {
+			t.Errorf("result = %q", result)
+		}
+		if strings.Contains(result, "Final") || strings.Contains(result, "First") {
+			t.Errorf("result includes entries outside the range: %q", result)
+		}
+	})
+}
+
+func TestBetweenMissingEndpoint(t *testing.T) {
+	content := "## [3.0.0]\n\nThree\n\n## [2.0.0]\n\nTwo\n\n## [1.0.0]\n\nOne\n"
+	p := Parse(content)
+
+	result, ok := p.Between("1.3.2", "2.1.0")
+	if !ok {
+		t.Fatal("expected result")
+	}
+	if result != "## [2.0.0]\n\nTwo" {
+		t.Errorf("result = %q", result)
+	}
+
+	result, ok = p.Between("0.5.0", "")
+	if !ok || !strings.HasPrefix(result, "## [3.0.0]") || !strings.HasSuffix(result, "One") {
+		t.Errorf("result = %q, %v", result, ok)
+	}
+
+	if _, ok := p.Between("Unreleased", "9.9.9"); ok {
+		t.Error("expected no result for an invalid endpoint")
+	}
+}
+
+func TestHeadersWithEpochs(t *testing.T) {
+	content := "## 1.0~rc1-1\n\nCandidate\n\n## 1:2.3-4\n\nEpoch\n\n## 1!2.0.0\n\nPython epoch\n"
+	p := ParseWithFormat(content, FormatMarkdown)
+	got := strings.Join(p.Versions(), ",")
+	if got != "1.0~rc1-1,1:2.3-4,1!2.0.0" {
+		t.Errorf("Versions() = %s", got)
+	}
+	// "1!2.0.0" is not a Debian version, so it stays at the front.
+	if sorted := strings.Join(p.WithScheme(Debian).SortedVersions(), ","); sorted != "1!2.0.0,1:2.3-4,1.0~rc1-1" {
+		t.Errorf("SortedVersions() = %s", sorted)
+	}
+}
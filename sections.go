@@ -0,0 +1,180 @@
+package changelog
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Category is the normalized kind of a changelog section, following the
+// Keep a Changelog vocabulary.
+type Category int
+
+const (
+	CategoryUnknown    Category = iota // Any heading not listed below
+	CategoryAdded                      // ### Added
+	CategoryChanged                    // ### Changed
+	CategoryDeprecated                 // ### Deprecated
+	CategoryRemoved                    // ### Removed
+	CategoryFixed                      // ### Fixed
+	CategorySecurity                   // ### Security
+)
+
+var categoryNames = map[Category]string{
+	CategoryUnknown:    "Unknown",
+	CategoryAdded:      "Added",
+	CategoryChanged:    "Changed",
+	CategoryDeprecated: "Deprecated",
+	CategoryRemoved:    "Removed",
+	CategoryFixed:      "Fixed",
+	CategorySecurity:   "Security",
+}
+
+// String returns the Keep a Changelog heading for the category.
+func (c Category) String() string {
+	if name, ok := categoryNames[c]; ok {
+		return name
+	}
+	return categoryNames[CategoryUnknown]
+}
+
+// categoryAliases maps common headings from other conventions (GNU NEWS,
+// CPAN::Changes groups, ...) to their category.
+var categoryAliases = map[string]Category{
+	"bug fixes":      CategoryFixed,
+	"bugfixes":       CategoryFixed,
+	"fixes":          CategoryFixed,
+	"new features":   CategoryAdded,
+	"features":       CategoryAdded,
+	"deprecations":   CategoryDeprecated,
+	"removals":       CategoryRemoved,
+	"security fixes": CategorySecurity,
+}
+
+// ParseCategory maps a section heading to its category. Matching is case
+// insensitive and ignores surrounding whitespace and a trailing colon.
+// Common synonyms such as "Bug Fixes" and "New Features" are recognised;
+// other headings outside the Keep a Changelog vocabulary return
+// CategoryUnknown.
+func ParseCategory(name string) Category {
+	name = strings.TrimSuffix(strings.TrimSpace(name), ":")
+	if c, ok := categoryAliases[strings.ToLower(name)]; ok {
+		return c
+	}
+	for c, n := range categoryNames {
+		if c != CategoryUnknown && strings.EqualFold(name, n) {
+			return c
+		}
+	}
+	return CategoryUnknown
+}
+
+// Section is a headed block inside a changelog entry, such as "### Added".
+// StartLine is the 0-based line of the heading and EndLine the last
+// non-blank line of the section.
+type Section struct {
+	Name      string
+	Category  Category
+	Content   string
+	Items     []Item
+	StartLine int
+	EndLine   int
+}
+
+var (
+	atxHeading    = regexp.MustCompile(`^#{1,6}\s+(.+?)\s*#*\s*$`)
+	setextHeading = regexp.MustCompile(`^(?:={3,}|-{3,})\s*$`)
+)
+
+// markdownHeading reports whether lines[i] begins an ATX (### Name) or
+// setext (Name\n----) heading. It returns the heading text and the number
+// of lines the heading occupies.
+func markdownHeading(lines []string, i int) (string, int, bool) {
+	if m := atxHeading.FindStringSubmatch(lines[i]); m != nil {
+		return m[1], 1, true
+	}
+	text := strings.TrimSpace(lines[i])
+	if text == "" || isListItem(lines[i]) || i+1 >= len(lines) {
+		return "", 0, false
+	}
+	if setextHeading.MatchString(lines[i+1]) {
+		return text, 2, true
+	}
+	return "", 0, false
+}
+
+var bracketHeadingLine = regexp.MustCompile(`^[ \t]*\[[ \t]*(.+?)[ \t]*\][ \t]*$`)
+
+// bracketHeading treats "[Name]" lines as headings: the groups of a
+// CPAN::Changes file, or the "[ Name ]" contributor blocks of a Debian
+// changelog.
+func bracketHeading(lines []string, i int) (string, int, bool) {
+	if m := bracketHeadingLine.FindStringSubmatch(lines[i]); m != nil {
+		return m[1], 1, true
+	}
+	return "", 0, false
+}
+
+func isListItem(line string) bool {
+	trimmed := strings.TrimLeft(line, " \t")
+	return len(trimmed) > 1 && strings.ContainsRune("-*+", rune(trimmed[0])) && (trimmed[1] == ' ' || trimmed[1] == '\t')
+}
+
+// headingFunc reports whether lines[i] begins a section heading, returning
+// the heading text and the number of lines it occupies.
+type headingFunc func(lines []string, i int) (string, int, bool)
+
+// parseBody splits an entry body into its headed sections, as recognised
+// by heading, and collects the bullet items of each part. firstLine is the
+// line number of the body's first line in the original content. Text
+// before the first heading belongs to the entry but to no section, so it
+// only appears in Entry.Content and, if it holds bullets, Entry.Items.
+func parseBody(body string, firstLine int, heading headingFunc) ([]Section, []Item) {
+	lines := strings.Split(body, "\n")
+	var sections []Section
+	var items []Item
+	var current *Section
+	start := 0
+
+	flush := func(end int) {
+		part := lines[start:end]
+		partItems := parseItems(part, firstLine+start)
+		items = append(items, partItems...)
+		if current != nil {
+			current.Content = strings.TrimSpace(strings.Join(part, "\n"))
+			current.Items = partItems
+			for i := end - 1; i >= start; i-- {
+				if strings.TrimSpace(lines[i]) != "" {
+					current.EndLine = firstLine + i
+					break
+				}
+			}
+			sections = append(sections, *current)
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		if name, n, ok := heading(lines, i); ok {
+			flush(i)
+			current = &Section{
+				Name:      name,
+				Category:  ParseCategory(name),
+				StartLine: firstLine + i,
+				EndLine:   firstLine + i + n - 1,
+			}
+			i += n - 1
+			start = i + 1
+		}
+	}
+	flush(len(lines))
+	return sections, items
+}
+
+// Section returns the first section of the entry with the given category.
+func (e Entry) Section(c Category) (Section, bool) {
+	for _, s := range e.Sections {
+		if s.Category == c {
+			return s, true
+		}
+	}
+	return Section{}, false
+}
@@ -0,0 +1,104 @@
+package changelog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSections(t *testing.T) {
+	content := mustReadFixture(t, "keep_a_changelog.md")
+	p := ParseWithFormat(content, FormatKeepAChangelog)
+
+	t.Run("ordered sections", func(t *testing.T) {
+		entry, _ := p.Entry("1.1.0")
+		if len(entry.Sections) != 2 {
+			t.Fatalf("expected 2 sections, got %d", len(entry.Sections))
+		}
+		if entry.Sections[0].Name != "Added" || entry.Sections[0].Category != CategoryAdded {
+			t.Errorf("section[0] = %q (%v), want Added", entry.Sections[0].Name, entry.Sections[0].Category)
+		}
+		if entry.Sections[1].Name != "Fixed" || entry.Sections[1].Category != CategoryFixed {
+			t.Errorf("section[1] = %q (%v), want Fixed", entry.Sections[1].Name, entry.Sections[1].Category)
+		}
+	})
+
+	t.Run("section content", func(t *testing.T) {
+		entry, _ := p.Entry("1.1.0")
+		added, ok := entry.Section(CategoryAdded)
+		if !ok {
+			t.Fatal("Added section not found")
+		}
+		if added.Content != "- User authentication system\n- OAuth2 support" {
+			t.Errorf("Added content = %q", added.Content)
+		}
+		if strings.Contains(added.Content, "Memory leak") {
+			t.Error("Added content should not include the Fixed section")
+		}
+	})
+
+	t.Run("missing category", func(t *testing.T) {
+		entry, _ := p.Entry("1.0.1")
+		if _, ok := entry.Section(CategorySecurity); ok {
+			t.Error("expected no Security section in 1.0.1")
+		}
+	})
+
+	t.Run("text before first heading", func(t *testing.T) {
+		p := Parse(mustReadFixture(t, "comprehensive.md"))
+		entry, _ := p.Entry("1.0.0")
+		if len(entry.Sections) != 1 || entry.Sections[0].Name != "Added" {
+			t.Fatalf("unexpected sections: %+v", entry.Sections)
+		}
+		if strings.Contains(entry.Sections[0].Content, "Initial release") {
+			t.Error("leading text should not belong to a section")
+		}
+	})
+
+	t.Run("unknown sections preserved", func(t *testing.T) {
+		p := Parse("## [1.0.0] - 2024-01-01\n\n### Performance\n\n- Faster\n\n### Security\n\n- Patched\n")
+		entry, _ := p.Entry("1.0.0")
+		if len(entry.Sections) != 2 {
+			t.Fatalf("expected 2 sections, got %d", len(entry.Sections))
+		}
+		if entry.Sections[0].Name != "Performance" || entry.Sections[0].Category != CategoryUnknown {
+			t.Errorf("section[0] = %q (%v), want Performance (Unknown)", entry.Sections[0].Name, entry.Sections[0].Category)
+		}
+		if entry.Sections[1].Category != CategorySecurity {
+			t.Errorf("section[1] category = %v, want Security", entry.Sections[1].Category)
+		}
+	})
+
+	t.Run("setext subheadings", func(t *testing.T) {
+		p := ParseWithFormat("1.0.0\n=====\n\nFixed\n-----\n\n- Bug\n", FormatUnderline)
+		entry, _ := p.Entry("1.0.0")
+		if len(entry.Sections) != 1 || entry.Sections[0].Category != CategoryFixed {
+			t.Fatalf("unexpected sections: %+v", entry.Sections)
+		}
+		if entry.Sections[0].Content != "- Bug" {
+			t.Errorf("content = %q, want %q", entry.Sections[0].Content, "- Bug")
+		}
+	})
+}
+
+func TestParseCategory(t *testing.T) {
+	tests := []struct {
+		name string
+		want Category
+	}{
+		{"Added", CategoryAdded},
+		{"changed", CategoryChanged},
+		{" DEPRECATED ", CategoryDeprecated},
+		{"Removed:", CategoryRemoved},
+		{"Fixed", CategoryFixed},
+		{"Security", CategorySecurity},
+		{"Bug Fixes", CategoryFixed},
+		{"New features", CategoryAdded},
+		{"Performance", CategoryUnknown},
+		{"", CategoryUnknown},
+	}
+	for _, tt := range tests {
+		if got := ParseCategory(tt.name); got != tt.want {
+			t.Errorf("ParseCategory(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package changelog
+
+import (
+	"bytes"
+	"io"
+	"iter"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// streamChunkSize is how much Stream reads before it first looks for
+// headers. It reads more when a chunk does not complete an entry.
+var streamChunkSize = 64 << 10
+
+// streamDetectSize is how much Stream reads to detect the format.
+var streamDetectSize = 64 << 10
+
+// Stream reads a changelog from r and yields its entries in the order they
+// appear, each as soon as the header of the next one has been read, so
+// only about one entry is held in memory at a time. Stopping the iteration
+// stops reading, which makes taking the newest few releases of a very
+// large changelog cheap:
+//
+//	for entry, err := range changelog.Stream(r, changelog.FormatAuto) {
+//		if err != nil {
+//			return err
+//		}
+//		// ...
+//		if n++; n == 3 {
+//			break
+//		}
+//	}
+//
+// FormatAuto detects the format from at least the first 64 KiB, reading
+// on until some format finds version headers. Entry.URL is
+// only set from link reference definitions read before the entry was
+// complete, so the definitions usually found at the end of a file are not
+// used; inline header links are. A read error is yielded once, with a zero
+// Entry, and ends the iteration.
+func Stream(r io.Reader, format Format) iter.Seq2[Entry, error] {
+	return func(yield func(Entry, error) bool) {
+		var (
+			buf       []byte
+			preamble  string
+			firstLine int // line number of the first line in buf
+			offset    int // byte offset of buf
+			scanAt    = streamChunkSize
+			chunk     = make([]byte, streamChunkSize)
+		)
+		for {
+			n, err := r.Read(chunk)
+			buf = append(buf, chunk[:n]...)
+			eof := err == io.EOF
+			if err != nil && !eof {
+				yield(Entry{}, err)
+				return
+			}
+			if !eof && (len(buf) < scanAt || (format.Handler() == nil && len(buf) < streamDetectSize)) {
+				continue
+			}
+
+			// Until the input ends, only complete lines are scanned, and the
+			// last header found starts an entry that may not be complete.
+			end := len(buf)
+			if !eof {
+				end = bytes.LastIndexByte(buf, '\n') + 1
+			}
+			// The text before the first header is kept in front of every
+			// chunk, as formats such as RPM and RST need it to find headers.
+			content := preamble + string(buf[:end])
+			if format.Handler() == nil {
+				// The format is only settled once some format finds headers,
+				// so a long preamble does not lock in the Markdown fallback.
+				if candidates := DetectFormat(content); len(candidates) > 0 && candidates[0].Score > 0 {
+					format = candidates[0].Format
+				} else if eof {
+					format = FormatMarkdown
+				} else {
+					scanAt = 2 * len(buf)
+					continue
+				}
+			}
+			cut := len(content)
+			if !eof {
+				headers := ParseWithFormat(content, format).findHeaders()
+				if len(headers) < 2 || headers[len(headers)-1].match[0] <= len(preamble) {
+					scanAt = 2 * len(buf)
+					continue
+				}
+				cut = headers[len(headers)-1].match[0]
+			}
+
+			p := ParseWithFormat(content[:cut], format)
+			p.firstLine = firstLine - strings.Count(preamble, "\n")
+			p.offset = offset - len(preamble)
+			entries := p.list()
+			for _, entry := range entries {
+				if !yield(entry, nil) {
+					return
+				}
+			}
+			if eof {
+				return
+			}
+			firstLine += strings.Count(content[len(preamble):cut], "\n")
+			offset += cut - len(preamble)
+			buf = append(buf[:0], buf[cut-len(preamble):]...)
+			if preamble == "" && len(entries) > 0 {
+				preamble = content[:p.entries[0].match[0]]
+			}
+			scanAt = len(buf) + streamChunkSize
+		}
+	}
+}
+
+// StreamFile opens a changelog file and streams its entries with Stream,
+// closing the file when the iteration ends. AsciiDoc and Org files are
+// read in those formats, as by ParseFile; the format of other files is
+// detected.
+func StreamFile(path string) iter.Seq2[Entry, error] {
+	return func(yield func(Entry, error) bool) {
+		f, err := os.Open(path)
+		if err != nil {
+			yield(Entry{}, err)
+			return
+		}
+		defer f.Close()
+		for entry, err := range Stream(f, extensionFormats[strings.ToLower(filepath.Ext(path))]) {
+			if !yield(entry, err) {
+				return
+			}
+		}
+	}
+}
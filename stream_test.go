@@ -0,0 +1,146 @@
+package changelog
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func collectStream(t *testing.T, r io.Reader, format Format) []Entry {
+	t.Helper()
+	var entries []Entry
+	for entry, err := range Stream(r, format) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestStreamMatchesParse(t *testing.T) {
+	defer func(chunk, detect int) { streamChunkSize, streamDetectSize = chunk, detect }(streamChunkSize, streamDetectSize)
+	streamDetectSize = 0
+
+	files, err := filepath.Glob(filepath.Join("testdata", "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range files {
+		if info, err := os.Stat(path); err != nil || info.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p := ParseWithFormat(string(data), extensionFormats[filepath.Ext(path)])
+		want := p.list()
+		for _, size := range []int{16, 100, 4096} {
+			t.Run(fmt.Sprintf("%s/%d", filepath.Base(path), size), func(t *testing.T) {
+				streamChunkSize = size
+				got := collectStream(t, iotest.HalfReader(strings.NewReader(string(data))), p.format)
+				if len(got) != len(want) {
+					t.Fatalf("streamed %d entries, want %d", len(got), len(want))
+				}
+				for i := range want {
+					// Link reference definitions at the end of the file
+					// are read after the entries have been yielded.
+					got[i].URL, want[i].URL = "", ""
+					if !reflect.DeepEqual(got[i], want[i]) {
+						t.Errorf("entry %d = %+v\nwant %+v", i, got[i], want[i])
+					}
+				}
+			})
+		}
+	}
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (c *countingReader) Read(b []byte) (int, error) {
+	n, err := c.r.Read(b)
+	c.n += n
+	return n, err
+}
+
+func TestStreamStopsEarly(t *testing.T) {
+	var b strings.Builder
+	for i := 5000; i > 0; i-- {
+		fmt.Fprintf(&b, "## [1.0.%d] - 2024-01-01\n\n### Fixed\n\n- Fix number %d.\n\n", i, i)
+	}
+	r := &countingReader{r: strings.NewReader(b.String())}
+
+	var versions []string
+	for entry, err := range Stream(r, FormatAuto) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		versions = append(versions, entry.Version)
+		if len(versions) == 3 {
+			break
+		}
+	}
+	if !slices.Equal(versions, []string{"1.0.5000", "1.0.4999", "1.0.4998"}) {
+		t.Errorf("versions = %v", versions)
+	}
+	if r.n > 2*streamChunkSize {
+		t.Errorf("read %d of %d bytes", r.n, b.Len())
+	}
+}
+
+func TestStreamLongPreamble(t *testing.T) {
+	content := "# Changelog\n\n" + strings.Repeat("Some introductory text that is not a version header.\n", 3000) +
+		"\n## [1.1.0] - 2024-03-15\n\n- Fix\n\n## [1.0.0] - 2024-01-10\n\n- First\n"
+	if len(content) <= streamDetectSize {
+		t.Fatalf("preamble of %d bytes does not exceed the detection size", len(content))
+	}
+	var versions []string
+	for _, entry := range collectStream(t, strings.NewReader(content), FormatAuto) {
+		versions = append(versions, entry.Version)
+	}
+	if got := strings.Join(versions, ","); got != "1.1.0,1.0.0" {
+		t.Errorf("versions = %q", got)
+	}
+}
+
+func TestStreamReadError(t *testing.T) {
+	boom := errors.New("boom")
+	var errs []error
+	for _, err := range Stream(iotest.ErrReader(boom), FormatMarkdown) {
+		errs = append(errs, err)
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], boom) {
+		t.Errorf("errors = %v", errs)
+	}
+}
+
+func TestStreamFile(t *testing.T) {
+	var versions []string
+	for entry, err := range StreamFile(filepath.Join("testdata", "NEWS.org")) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		versions = append(versions, entry.Version)
+	}
+	if got := strings.Join(versions, ","); got != "Unreleased,1.2.0,1.1.0" {
+		t.Errorf("versions = %s", got)
+	}
+
+	for _, err := range StreamFile(filepath.Join("testdata", "missing.md")) {
+		if !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("err = %v", err)
+		}
+	}
+}
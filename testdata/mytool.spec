@@ -0,0 +1,25 @@
+Name:           mytool
+Version:        1.2.3
+Release:        1%{?dist}
+Summary:        An example tool
+
+%description
+An example tool.
+
+%files
+%{_bindir}/mytool
+
+%changelog
+* Fri Mar 15 2024 Jane Doe <jane@example.com> - 1.2.3-1
+- Update to 1.2.3
+- Fix crash when the config file
+  is empty
+
+* Mon Jan  8 2024 John Smith <john@example.org> 1:1.2.2-2
+- Rebuild for new toolchain
+
+* Thu Dec 07 2023 Jane Doe <jane@example.com>
+- Spec cleanup
+
+* Wed Nov 01 2023 Jane Doe - 1.2.0-1
+- Initial package
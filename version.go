@@ -0,0 +1,139 @@
+package changelog
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Version is a semantic version as defined by SemVer 2.0
+// (https://semver.org). Parsing is lenient in two ways common in
+// changelogs: a leading "v" is allowed, and missing minor or patch numbers
+// default to zero.
+type Version struct {
+	Major      uint64
+	Minor      uint64
+	Patch      uint64
+	Prerelease []string // dot-separated identifiers, e.g. ["beta", "2"]
+	Build      []string // build metadata identifiers, ignored for ordering
+	Original   string   // the string the version was parsed from
+}
+
+var semverPattern = regexp.MustCompile(`^[vV]?(\d+)(?:\.(\d+))?(?:\.(\d+))?` +
+	`(?:-([0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*))?` +
+	`(?:\+([0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*))?$`)
+
+// ParseVersion parses a semantic version string such as "1.5.0-beta.2" or
+// "v2.0.0+build.7".
+func ParseVersion(s string) (Version, error) {
+	m := semverPattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return Version{}, fmt.Errorf("invalid semantic version %q", s)
+	}
+	v := Version{Original: s}
+	for i, dst := range []*uint64{&v.Major, &v.Minor, &v.Patch} {
+		if m[i+1] == "" {
+			continue
+		}
+		n, err := strconv.ParseUint(m[i+1], 10, 64)
+		if err != nil {
+			return Version{}, fmt.Errorf("invalid semantic version %q: %w", s, err)
+		}
+		*dst = n
+	}
+	if m[4] != "" {
+		v.Prerelease = strings.Split(m[4], ".")
+	}
+	if m[5] != "" {
+		v.Build = strings.Split(m[5], ".")
+	}
+	return v, nil
+}
+
+// String returns the canonical form of the version, without a "v" prefix.
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if len(v.Prerelease) > 0 {
+		s += "-" + strings.Join(v.Prerelease, ".")
+	}
+	if len(v.Build) > 0 {
+		s += "+" + strings.Join(v.Build, ".")
+	}
+	return s
+}
+
+// Compare returns -1, 0 or +1 depending on whether v has lower, equal or
+// higher precedence than o. Build metadata does not affect precedence.
+func (v Version) Compare(o Version) int {
+	if c := cmp.Compare(v.Major, o.Major); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(v.Minor, o.Minor); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(v.Patch, o.Patch); c != 0 {
+		return c
+	}
+	return comparePrerelease(v.Prerelease, o.Prerelease)
+}
+
+// comparePrerelease orders prerelease identifiers per SemVer: a version
+// without a prerelease is higher, numeric identifiers compare numerically
+// and sort below alphanumeric ones, and a longer list wins a tie.
+func comparePrerelease(a, b []string) int {
+	switch {
+	case len(a) == 0 && len(b) == 0:
+		return 0
+	case len(a) == 0:
+		return 1
+	case len(b) == 0:
+		return -1
+	}
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if c := compareIdentifier(a[i], b[i]); c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(len(a), len(b))
+}
+
+func compareIdentifier(a, b string) int {
+	an, aErr := strconv.ParseUint(a, 10, 64)
+	bn, bErr := strconv.ParseUint(b, 10, 64)
+	switch {
+	case aErr == nil && bErr == nil:
+		return cmp.Compare(an, bn)
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// SortVersions sorts versions in ascending order of precedence. Versions
+// with equal precedence keep their relative order.
+func SortVersions(versions []Version) {
+	slices.SortStableFunc(versions, Version.Compare)
+}
+
+// SortedVersions returns the changelog's versions newest first by the
+// precedence of the parser's scheme (SemVer by default), independent of
+// their order in the file. Versions that are not valid in the scheme, such
+// as "Unreleased", come first in file order.
+func (p *Parser) SortedVersions() []string {
+	scheme := p.versionScheme()
+	var invalid, valid []string
+	for _, v := range p.Versions() {
+		if scheme.Valid(v) {
+			valid = append(valid, v)
+		} else {
+			invalid = append(invalid, v)
+		}
+	}
+	slices.SortStableFunc(valid, func(a, b string) int { return scheme.Compare(b, a) })
+	return append(invalid, valid...)
+}
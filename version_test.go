@@ -0,0 +1,109 @@
+package changelog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "1.2.3", want: "1.2.3"},
+		{in: "v1.2.3", want: "1.2.3"},
+		{in: "2.0.0-x.7.z.92", want: "2.0.0-x.7.z.92"},
+		{in: "1.5.0-beta.2", want: "1.5.0-beta.2"},
+		{in: "1.0.0+build.123", want: "1.0.0+build.123"},
+		{in: "1.0.0-rc.1+exp.sha.5114f85", want: "1.0.0-rc.1+exp.sha.5114f85"},
+		{in: "1.2", want: "1.2.0"},
+		{in: "Unreleased", wantErr: true},
+		{in: "1.2.3.4", wantErr: true},
+		{in: "1.0.0-", wantErr: true},
+		{in: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		v, err := ParseVersion(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseVersion(%q) expected error", tt.in)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseVersion(%q) error: %v", tt.in, err)
+			continue
+		}
+		if v.String() != tt.want {
+			t.Errorf("ParseVersion(%q) = %s, want %s", tt.in, v, tt.want)
+		}
+		if v.Original != tt.in {
+			t.Errorf("Original = %q, want %q", v.Original, tt.in)
+		}
+	}
+}
+
+func TestVersionCompare(t *testing.T) {
+	// Ascending precedence, from the SemVer 2.0 specification.
+	ordered := []string{
+		"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta",
+		"1.0.0-beta.2", "1.0.0-beta.11", "1.0.0-rc.1", "1.0.0", "1.0.1",
+		"1.5.0-beta.2", "1.10.0", "2.0.0-x.7.z.92", "2.0.0",
+	}
+	for i := 0; i+1 < len(ordered); i++ {
+		a, _ := ParseVersion(ordered[i])
+		b, _ := ParseVersion(ordered[i+1])
+		if a.Compare(b) != -1 || b.Compare(a) != 1 {
+			t.Errorf("expected %s < %s", ordered[i], ordered[i+1])
+		}
+	}
+
+	a, _ := ParseVersion("1.0.0+build.1")
+	b, _ := ParseVersion("1.0.0+build.2")
+	if a.Compare(b) != 0 {
+		t.Error("build metadata should not affect precedence")
+	}
+}
+
+func TestSortVersions(t *testing.T) {
+	var vs []Version
+	for _, s := range []string{"1.10.0", "1.2.0", "1.2.0-rc.1", "0.9.0"} {
+		v, _ := ParseVersion(s)
+		vs = append(vs, v)
+	}
+	SortVersions(vs)
+	var got []string
+	for _, v := range vs {
+		got = append(got, v.Original)
+	}
+	if strings.Join(got, ",") != "0.9.0,1.2.0-rc.1,1.2.0,1.10.0" {
+		t.Errorf("sorted = %v", got)
+	}
+}
+
+func TestSortedVersions(t *testing.T) {
+	t.Run("comprehensive fixture", func(t *testing.T) {
+		p := Parse(mustReadFixture(t, "comprehensive.md"))
+		got := p.SortedVersions()
+		want := []string{
+			"Unreleased", "2.0.0-x.7.z.92", "1.5.0-beta.2", "1.4.0-rc.1",
+			"1.3.0", "1.2.0", "1.1.0", "1.0.0",
+		}
+		if strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("SortedVersions() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("independent of file order", func(t *testing.T) {
+		p := Parse("## [1.0.0]\n## [Unreleased]\n## [1.10.0]\n## [1.2.0]\n## [2.0.0-beta.1]\n")
+		got := p.SortedVersions()
+		want := []string{"Unreleased", "2.0.0-beta.1", "1.10.0", "1.2.0", "1.0.0"}
+		if strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("SortedVersions() = %v, want %v", got, want)
+		}
+		if p.Versions()[0] != "1.0.0" {
+			t.Error("Versions() should keep file order")
+		}
+	})
+}
@@ -0,0 +1,205 @@
+package changelog
+
+import (
+	"io"
+	"strings"
+)
+
+// Render serializes entries, in the order given, in the given format.
+// FormatAuto renders Keep a Changelog.
+//
+// The body of each entry is built from its Sections when it has any, then
+// from its Items, and otherwise from its raw Content. Sections are written
+// as "### Name" headings (in the equivalent style of other formats, or as
+// one list in formats without headings), falling back to the category
+// name when Name is empty, followed by their items or content. Output is
+// deterministic and parses back with ParseWithFormat into the same
+// versions, dates, sections and items, with these exceptions: RPM entries
+// need a date and a version-release, and GNU ChangeLog entries a date and
+// an Author in "Name <email>" form, so others do not parse back; a Debian
+// entry's date only parses back when its Author is in "Name <email>" form,
+// as the trailer line needs a maintainer; and an Unreleased entry in a
+// CPAN::Changes file is written as "{{$NEXT}}", which parses back as that
+// version, marked Unreleased.
+func Render(format Format, entries []Entry) string {
+	parts := make([]string, 0, len(entries))
+	for _, e := range entries {
+		parts = append(parts, renderEntry(format, e))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return strings.Join(parts, "\n\n") + "\n"
+}
+
+// Write renders entries like Render and writes the result to w.
+func Write(w io.Writer, format Format, entries []Entry) error {
+	_, err := io.WriteString(w, Render(format, entries))
+	return err
+}
+
+// Render serializes the parsed entries back to Markdown in the given format.
+func (p *Parser) Render(format Format) string {
+	return Render(format, p.list())
+}
+
+func (p *Parser) list() []Entry {
+	p.ensureParsed()
+	entries := make([]Entry, len(p.entries))
+	for i, ve := range p.entries {
+		entries[i] = ve.entry
+	}
+	return entries
+}
+
+func renderEntry(format Format, e Entry) string {
+	entry := renderHeader(format, e)
+	if body := renderBody(format, e); body != "" {
+		if format == FormatRPM {
+			entry += "\n" + body
+		} else {
+			entry += "\n\n" + body
+		}
+	}
+	if format == FormatDebian {
+		if trailer := renderDebianTrailer(e); trailer != "" {
+			entry += "\n\n" + trailer
+		}
+	}
+	return entry
+}
+
+func renderHeader(format Format, e Entry) string {
+	var date string
+	if e.Date != nil {
+		date = e.Date.Format("2006-01-02")
+	}
+
+	switch format {
+	case FormatDebian:
+		return renderDebianHeader(e)
+	case FormatRPM:
+		return renderRPMHeader(e)
+	case FormatGNUNews:
+		return renderGNUNewsHeader(e)
+	case FormatGNUChangeLog:
+		return renderGNUChangeLogHeader(e)
+	case FormatCPAN:
+		return renderCPANHeader(e)
+	case FormatCabal:
+		return renderCabalHeader(e)
+	case FormatOrg:
+		return renderOrgHeader(e)
+	case FormatAsciiDoc:
+		if date != "" {
+			return "== " + e.Version + " (" + date + ")"
+		}
+		return "== " + e.Version
+	case FormatMarkdown:
+		if date != "" {
+			return "## " + e.Version + " (" + date + ")"
+		}
+		return "## " + e.Version
+	case FormatRDoc:
+		return renderRDocHeader(e)
+	case FormatUnderline, FormatRST:
+		title := e.Version
+		if date != "" {
+			title += " (" + date + ")"
+		}
+		return title + "\n" + strings.Repeat("=", len(title))
+	default:
+		if date != "" {
+			return "## [" + e.Version + "] - " + date
+		}
+		return "## [" + e.Version + "]"
+	}
+}
+
+func renderBody(format Format, e Entry) string {
+	if (format == FormatRPM || format == FormatGNUChangeLog) && len(e.Sections) > 0 {
+		// These formats have no headings, so sections become one list.
+		var items []Item
+		for _, s := range e.Sections {
+			items = append(items, s.Items...)
+		}
+		return renderItems(format, items)
+	}
+	if len(e.Sections) > 0 {
+		parts := make([]string, 0, len(e.Sections))
+		for _, s := range e.Sections {
+			parts = append(parts, renderSection(format, s))
+		}
+		return strings.Join(parts, "\n\n")
+	}
+	if len(e.Items) > 0 {
+		return renderItems(format, e.Items)
+	}
+	content := strings.TrimSpace(e.Content)
+	if (format == FormatDebian || format == FormatCPAN) && content != "" {
+		content = "  " + content
+	}
+	return content
+}
+
+func renderSection(format Format, s Section) string {
+	name := s.Name
+	if name == "" {
+		name = s.Category.String()
+	}
+	heading, sep := "### "+name, "\n\n"
+	switch format {
+	case FormatRST:
+		heading = name + "\n" + strings.Repeat("-", len(name))
+	case FormatRDoc:
+		heading = "==== " + name
+	case FormatAsciiDoc:
+		heading = "=== " + name
+	case FormatOrg:
+		heading = "** " + name
+	case FormatDebian:
+		heading, sep = "  [ "+name+" ]", "\n"
+	case FormatCPAN:
+		heading, sep = "  ["+name+"]", "\n"
+	case FormatGNUNews:
+		heading = "** " + name
+	}
+
+	body := strings.TrimSpace(s.Content)
+	if len(s.Items) > 0 {
+		body = renderItems(format, s.Items)
+	}
+	if body == "" {
+		return heading
+	}
+	return heading + sep + body
+}
+
+// renderItems writes items as "- " bullets, indented in CPAN::Changes
+// files, as indented "* " bullets in Debian changelogs and GNU ChangeLogs,
+// or as AsciiDoc "*" bullets.
+func renderItems(format Format, items []Item) string {
+	var b strings.Builder
+	if format == FormatAsciiDoc {
+		renderAsciiDocItems(&b, items, 1)
+		return strings.TrimSuffix(b.String(), "\n")
+	}
+	indent, marker := "", "- "
+	switch format {
+	case FormatDebian:
+		indent, marker = "  ", "* "
+	case FormatCPAN:
+		indent = "  "
+	case FormatGNUChangeLog:
+		indent, marker = "\t", "* "
+	}
+	writeItems(&b, items, indent, marker)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func writeItems(b *strings.Builder, items []Item, indent, marker string) {
+	for _, it := range items {
+		b.WriteString(indent + marker + it.Text + "\n")
+		writeItems(b, it.Children, indent+"  ", marker)
+	}
+}
@@ -0,0 +1,126 @@
+package changelog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func sampleEntries() []Entry {
+	date := time.Date(2024, time.March, 15, 0, 0, 0, 0, time.UTC)
+	return []Entry{
+		{
+			Version: "1.1.0",
+			Date:    &date,
+			Sections: []Section{
+				{Name: "Added", Category: CategoryAdded, Items: []Item{
+					{Text: "OAuth2 support", Children: []Item{{Text: "GitHub provider"}}},
+				}},
+				{Category: CategoryFixed, Items: []Item{{Text: "Memory leak"}}},
+			},
+		},
+		{
+			Version: "1.0.0",
+			Items:   []Item{{Text: "Initial release"}},
+		},
+	}
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		format Format
+		want   string
+	}{
+		{
+			format: FormatKeepAChangelog,
+			want: "## [1.1.0] - 2024-03-15\n\n### Added\n\n- OAuth2 support\n  - GitHub provider\n\n### Fixed\n\n- Memory leak\n\n" +
+				"## [1.0.0]\n\n- Initial release\n",
+		},
+		{
+			format: FormatMarkdown,
+			want: "## 1.1.0 (2024-03-15)\n\n### Added\n\n- OAuth2 support\n  - GitHub provider\n\n### Fixed\n\n- Memory leak\n\n" +
+				"## 1.0.0\n\n- Initial release\n",
+		},
+		{
+			format: FormatUnderline,
+			want: "1.1.0 (2024-03-15)\n==================\n\n### Added\n\n- OAuth2 support\n  - GitHub provider\n\n### Fixed\n\n- Memory leak\n\n" +
+				"1.0.0\n=====\n\n- Initial release\n",
+		},
+	}
+
+	for _, tt := range tests {
+		got := Render(tt.format, sampleEntries())
+		if got != tt.want {
+			t.Errorf("Render(%v) =\n%s\nwant:\n%s", tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestRenderRoundTrip(t *testing.T) {
+	for _, format := range []Format{FormatKeepAChangelog, FormatMarkdown, FormatUnderline} {
+		p := ParseWithFormat(Render(format, sampleEntries()), format)
+
+		versions := p.Versions()
+		if len(versions) != 2 || versions[0] != "1.1.0" || versions[1] != "1.0.0" {
+			t.Fatalf("format %v: versions = %v", format, versions)
+		}
+
+		entry, _ := p.Entry("1.1.0")
+		assertDate(t, entry.Date, 2024, time.March, 15)
+		if len(entry.Sections) != 2 || entry.Sections[1].Name != "Fixed" {
+			t.Errorf("format %v: sections = %+v", format, entry.Sections)
+		}
+		if len(entry.Items) != 2 || len(entry.Items[0].Children) != 1 {
+			t.Errorf("format %v: items = %+v", format, entry.Items)
+		}
+
+		// Rendering the parsed model again is stable.
+		again := p.Render(format)
+		if again != Render(format, sampleEntries()) {
+			t.Errorf("format %v: re-render differs:\n%s", format, again)
+		}
+	}
+
+	t.Run("unreleased", func(t *testing.T) {
+		entries := append([]Entry{{Version: "Unreleased", Unreleased: true, Items: []Item{{Text: "Pending"}}}}, sampleEntries()...)
+		for f := FormatKeepAChangelog; f <= FormatOrg; f++ {
+			if f == FormatRPM || f == FormatGNUChangeLog {
+				continue // every entry needs a date
+			}
+			p := ParseWithFormat(Render(f, entries), f)
+			want := "Unreleased,1.1.0,1.0.0"
+			if f == FormatCPAN {
+				want = "{{$NEXT}},1.1.0,1.0.0"
+			}
+			if got := strings.Join(p.Versions(), ","); got != want {
+				t.Errorf("format %v: versions = %s, want %s", f, got, want)
+				continue
+			}
+			if entry, ok := p.Unreleased(); !ok || len(entry.Items) != 1 {
+				t.Errorf("format %v: Unreleased() = %+v, %v", f, entry, ok)
+			}
+		}
+	})
+}
+
+func TestRenderContentFallback(t *testing.T) {
+	got := Render(FormatKeepAChangelog, []Entry{{Version: "Unreleased", Content: "\nWork in progress.\n"}})
+	want := "## [Unreleased]\n\nWork in progress.\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if Render(FormatMarkdown, nil) != "" {
+		t.Error("expected empty output for no entries")
+	}
+}
+
+func TestWrite(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatMarkdown, sampleEntries()); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != Render(FormatMarkdown, sampleEntries()) {
+		t.Error("Write output differs from Render")
+	}
+}